@@ -0,0 +1,78 @@
+// Package charset 处理支付宝请求和异步通知参数的编码格式，支持utf-8、gbk和gb2312，
+// gb2312是gbk的子集，两者都按GBK编码转换
+package charset
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 支持的编码格式
+const (
+	UTF8   = "utf-8"
+	GBK    = "gbk"
+	GB2312 = "gb2312"
+)
+
+// Normalize 将编码格式名称转换成支付宝使用的小写形式，如"UTF8"、"GBK"转换成"utf-8"、"gbk"，
+// 不支持的名称仅转换成小写
+func Normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "utf8" {
+		return UTF8
+	}
+	return name
+}
+
+// Supported 判断是否支持该编码格式，为空时视为utf-8
+func Supported(name string) bool {
+	_, err := lookup(name)
+	return err == nil
+}
+
+// Encode 将UTF-8字符串转换成name指定的编码，返回的字符串保存的是转换后的字节，
+// name为空或utf-8时原样返回
+func Encode(name, s string) (string, error) {
+	enc, err := lookup(name)
+	if err != nil {
+		return "", err
+	}
+	if enc == nil {
+		return s, nil
+	}
+	result, err := enc.NewEncoder().String(s)
+	if err != nil {
+		return "", errors.New("参数值无法转换成" + Normalize(name) + "编码：" + err.Error())
+	}
+	return result, nil
+}
+
+// Decode 将name指定编码的字符串转换成UTF-8，name为空或utf-8时原样返回
+func Decode(name, s string) (string, error) {
+	enc, err := lookup(name)
+	if err != nil {
+		return "", err
+	}
+	if enc == nil {
+		return s, nil
+	}
+	result, err := enc.NewDecoder().String(s)
+	if err != nil {
+		return "", errors.New("参数值无法按" + Normalize(name) + "编码解码：" + err.Error())
+	}
+	return result, nil
+}
+
+// 获得编码格式对应的编码器，utf-8返回nil
+func lookup(name string) (encoding.Encoding, error) {
+	switch Normalize(name) {
+	case "", UTF8:
+		return nil, nil
+	case GBK, GB2312:
+		return simplifiedchinese.GBK, nil
+	}
+	return nil, errors.New("不支持的编码格式：" + name)
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+
+	"github.com/dxvgef/alipay/errs"
+)
+
+// 设置接口内容加密密钥(Base64编码的AES密钥)，设置后请求的biz_content会以encrypt_type=AES加密发送，
+// 加密的响应和异步通知会被自动解密
+func (obj *Config) SetEncryptKey(value string) error {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return obj.Localize(errs.Wrap(errs.ErrInvalidKey, "接口内容加密密钥必须是Base64编码的字符串"))
+	}
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return obj.Localize(errs.Wrap(errs.ErrInvalidKey, "接口内容加密密钥的长度必须是128、192或256位"))
+	}
+	obj.mutex.Lock()
+	obj.encryptKey = key
+	obj.mutex.Unlock()
+	return nil
+}
+
+// 获得接口内容加密密钥，未设置时返回nil
+func (obj *Config) GetEncryptKey() []byte {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+	return obj.encryptKey
+}
+
+// 获得接口内容加密方式，未设置加密密钥时返回空字符串
+func (obj *Config) GetEncryptType() string {
+	if obj.GetEncryptKey() == nil {
+		return ""
+	}
+	return "AES"
+}
+
+// Encrypt 使用接口内容加密密钥以AES/CBC/PKCS5Padding(IV全为0)加密data，返回Base64编码的密文
+func (obj *Config) Encrypt(data []byte) (string, error) {
+	block, err := obj.newCipherBlock()
+	if err != nil {
+		return "", err
+	}
+	padding := block.BlockSize() - len(data)%block.BlockSize()
+	plainText := append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipherText := make([]byte, len(plainText))
+	cipher.NewCBCEncrypter(block, make([]byte, block.BlockSize())).CryptBlocks(cipherText, plainText)
+	return base64.StdEncoding.EncodeToString(cipherText), nil
+}
+
+// Decrypt 使用接口内容加密密钥解密Base64编码的密文
+func (obj *Config) Decrypt(data string) ([]byte, error) {
+	block, err := obj.newCipherBlock()
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, errs.Wrap(errs.ErrDecrypt, "密文必须是Base64编码的字符串")
+	}
+	if len(cipherText) == 0 || len(cipherText)%block.BlockSize() != 0 {
+		return nil, errs.Wrap(errs.ErrDecrypt, "密文长度无效")
+	}
+	plainText := make([]byte, len(cipherText))
+	cipher.NewCBCDecrypter(block, make([]byte, block.BlockSize())).CryptBlocks(plainText, cipherText)
+
+	padding := int(plainText[len(plainText)-1])
+	if padding == 0 || padding > block.BlockSize() || padding > len(plainText) {
+		return nil, errs.Wrap(errs.ErrDecrypt, "解密失败，密钥可能不正确")
+	}
+	return plainText[:len(plainText)-padding], nil
+}
+
+// 创建AES加密块
+func (obj *Config) newCipherBlock() (cipher.Block, error) {
+	key := obj.GetEncryptKey()
+	if key == nil {
+		return nil, errors.New("未设置支付宝配置的接口内容加密密钥")
+	}
+	return aes.NewCipher(key)
+}
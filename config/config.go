@@ -1,59 +1,113 @@
 package config
 
 import (
+	"crypto"
+	"crypto/ecdsa"
 	"crypto/rsa"
-	"crypto/x509"
 	"errors"
 	"io/ioutil"
-	"log"
 	"strings"
+	"sync"
 
+	"github.com/dxvgef/alipay/errs"
 	"github.com/dxvgef/gommon/encrypt"
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
 )
 
-// 支付宝参数配置
+// 支付宝参数配置，可在运行时并发读取和更新
 type Config struct {
-	appID string // 应用ID
-	// alipayRootCert   *x509.Certificate // 支付宝根证书
-	alipayRootCertSN string         // 根证书SN的MD5值
-	alipayPublicKey  *rsa.PublicKey // 支付宝公钥
+	mutex sync.RWMutex // 密钥材料的读写锁
+	material
+}
+
+// 密钥材料
+type material struct {
+	appID               string                      // 应用ID
+	alipayRootCerts     []*x509.Certificate         // 支付宝根证书
+	alipayRootCertSN    string                      // RSA根证书SN的MD5值
+	alipayRootCertSNSM2 string                      // SM2根证书SN的MD5值
+	alipayCertSN        string                      // 当前支付宝公钥证书SN的MD5值
+	alipayPublicKey     crypto.PublicKey            // 当前支付宝公钥(*rsa.PublicKey或*sm2.PublicKey)
+	alipayPublicKeys    map[string]crypto.PublicKey // 按证书SN索引的支付宝公钥，用于支付宝轮换证书
+
+	appPublicKey       crypto.PublicKey // 应用公钥(*rsa.PublicKey或*sm2.PublicKey)
+	appCertPublicKeySN string           // 应用公钥证书SN的MD5值
+	appPrivateKey      *rsa.PrivateKey  // 应用RSA私钥
+	appSM2PrivateKey   *sm2.PrivateKey  // 应用SM2私钥
+	appPrivateKeyType  string           // 应用私钥类型
+	appSigner          Signer           // 应用签名器，为nil时使用应用私钥签名
+	appSignType        string           // 应用签名类型RSA/RSA2/SM2
+	encryptKey         []byte           // 接口内容加密密钥
+	locale             string           // 错误信息的语言
+}
 
-	appPublicKey       *rsa.PublicKey  // 应用公钥
-	appCertPublicKeySN string          // 应用公钥证书SN的MD5值
-	appPrivateKey      *rsa.PrivateKey // 应用私钥
-	appPrivateKeyType  string          // 应用私钥类型
-	appSignType        string          // 应用签名类型RSA/RSA2
+// 复制密钥材料，按SN索引的支付宝公钥会被复制到新的map中
+func (m material) clone() material {
+	keys := make(map[string]crypto.PublicKey, len(m.alipayPublicKeys))
+	for sn := range m.alipayPublicKeys {
+		keys[sn] = m.alipayPublicKeys[sn]
+	}
+	m.alipayPublicKeys = keys
+	return m
+}
+
+// Update 在配置副本上执行fn，fn返回nil时将副本中的密钥材料一次性替换到当前配置，
+// 否则丢弃副本，当前配置保持不变。用于在运行时原子地轮换多项密钥材料。
+// 执行期间持有当前配置的写锁，其它读取和更新会等待Update完成，因此fn只能操作传入的副本，不能调用当前配置的方法
+func (obj *Config) Update(fn func(*Config) error) error {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	var tmp Config
+	tmp.material = obj.material.clone()
+	if err := fn(&tmp); err != nil {
+		return err
+	}
+	obj.material = tmp.material
+	return nil
 }
 
 // 加载支付宝根证书文件
 func (obj *Config) LoadAlipayRootCert(filePath string) error {
 	fileData, err := ioutil.ReadFile(filePath)
 	if err != nil {
-		return err
+		return obj.Localize(err)
 	}
 
 	// 解析PEM块
 	blocks := encrypt.ParsePEMBlocks(fileData)
 	if blocks == nil {
-		return errors.New("支付宝根证书数据格式无效")
+		return obj.Localize(errs.Wrap(errs.ErrInvalidCert, "支付宝根证书数据格式无效"))
 	}
 
-	// 计算根证书的SN
-	var SNSlice []string
+	// 分别计算RSA和SM2根证书的SN
+	var SNSlice, SM2SNSlice []string
+	var certs []*x509.Certificate
 	for k := range blocks {
-		sn, err := parseCert(blocks[k].Bytes)
+		cert, sn, sm2SN, err := parseCert(blocks[k].Bytes)
 		if err != nil {
-			return err
+			return obj.Localize(err)
+		}
+		if cert != nil {
+			certs = append(certs, cert)
 		}
 		if sn != "" {
 			SNSlice = append(SNSlice, sn)
 		}
+		if sm2SN != "" {
+			SM2SNSlice = append(SM2SNSlice, sm2SN)
+		}
 	}
 
-	if len(SNSlice) == 0 {
-		return errors.New("支付宝根证书的SN计算失败")
+	if len(SNSlice) == 0 && len(SM2SNSlice) == 0 {
+		return obj.Localize(errors.New("支付宝根证书的SN计算失败"))
 	}
+	obj.mutex.Lock()
+	obj.alipayRootCerts = certs
 	obj.alipayRootCertSN = strings.Join(SNSlice, "_")
+	obj.alipayRootCertSNSM2 = strings.Join(SM2SNSlice, "_")
+	obj.mutex.Unlock()
 
 	return nil
 }
@@ -62,53 +116,133 @@ func (obj *Config) LoadAlipayRootCert(filePath string) error {
 func (obj *Config) LoadAlipayCertPublicKey(filePath string) error {
 	fileData, err := ioutil.ReadFile(filePath)
 	if err != nil {
-		return err
+		return obj.Localize(err)
 	}
 
 	blocks := encrypt.ParsePEMBlocks(fileData)
 	if blocks == nil {
-		return errors.New("支付宝公钥证书格式无效")
+		return obj.Localize(errs.Wrap(errs.ErrInvalidCert, "支付宝公钥证书格式无效"))
 	}
 
-	publicKey, err := encrypt.ParseRSAPublicKey(blocks[0].Bytes)
+	cert, err := x509.ParseCertificate(blocks[0].Bytes)
 	if err != nil {
-		return err
+		return obj.Localize(err)
+	}
+	publicKey, err := certPublicKey(cert)
+	if err != nil {
+		return obj.Localize(err)
+	}
+
+	// 计算支付宝公钥证书的SN
+	sn, err := certSN(cert)
+	if err != nil {
+		return obj.Localize(err)
 	}
 
+	obj.mutex.Lock()
 	obj.alipayPublicKey = publicKey
+	obj.alipayCertSN = sn
+	obj.storeAlipayPublicKey(sn, publicKey)
+	obj.mutex.Unlock()
 
 	return nil
 }
 
+// 添加支付宝公钥证书，证书必须由已加载的支付宝根证书签发，返回证书的SN
+// 用于支付宝轮换公钥证书后缓存新证书，不会改变当前默认使用的支付宝公钥
+func (obj *Config) AddAlipayCert(data []byte) (string, error) {
+	obj.mutex.RLock()
+	rootCerts := obj.alipayRootCerts
+	obj.mutex.RUnlock()
+	if len(rootCerts) == 0 {
+		return "", obj.Localize(errors.New("未加载支付宝根证书，无法校验支付宝公钥证书"))
+	}
+
+	blocks := encrypt.ParsePEMBlocks(data)
+	if blocks == nil {
+		return "", obj.Localize(errs.Wrap(errs.ErrInvalidCert, "支付宝公钥证书格式无效"))
+	}
+
+	var certs []*x509.Certificate
+	for k := range blocks {
+		cert, err := x509.ParseCertificate(blocks[k].Bytes)
+		if err != nil {
+			return "", obj.Localize(errs.Wrap(errs.ErrInvalidCert, "支付宝公钥证书格式无效："+err.Error()))
+		}
+		certs = append(certs, cert)
+	}
+
+	// 使用根证书校验证书链，证书文件中除第一个证书外的其它证书作为中间证书
+	roots := x509.NewCertPool()
+	for k := range rootCerts {
+		roots.AddCert(rootCerts[k])
+	}
+	intermediates := x509.NewCertPool()
+	for k := range certs[1:] {
+		intermediates.AddCert(certs[k+1])
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return "", obj.Localize(errs.Wrap(errs.ErrCertVerify, "支付宝公钥证书校验失败："+err.Error()))
+	}
+
+	publicKey, err := certPublicKey(certs[0])
+	if err != nil {
+		return "", obj.Localize(err)
+	}
+	sn, err := certSN(certs[0])
+	if err != nil {
+		return "", obj.Localize(err)
+	}
+	obj.mutex.Lock()
+	obj.storeAlipayPublicKey(sn, publicKey)
+	obj.mutex.Unlock()
+
+	return sn, nil
+}
+
+// 缓存支付宝公钥，调用方需持有写锁
+func (obj *Config) storeAlipayPublicKey(sn string, publicKey crypto.PublicKey) {
+	if obj.alipayPublicKeys == nil {
+		obj.alipayPublicKeys = make(map[string]crypto.PublicKey)
+	}
+	obj.alipayPublicKeys[sn] = publicKey
+}
+
 // 加载应用公钥证书文件
 func (obj *Config) LoadAppCertPublicKey(filePath string) error {
 	fileData, err := ioutil.ReadFile(filePath)
 	if err != nil {
-		return err
+		return obj.Localize(err)
 	}
 
 	blocks := encrypt.ParsePEMBlocks(fileData)
 	if blocks == nil {
-		return errors.New("支付宝应用公钥证书格式无效")
+		return obj.Localize(errs.Wrap(errs.ErrInvalidCert, "支付宝应用公钥证书格式无效"))
 	}
 
-	publicKey, err := encrypt.ParseRSAPublicKey(blocks[0].Bytes)
+	cert, err := x509.ParseCertificate(blocks[0].Bytes)
 	if err != nil {
-		return err
+		return obj.Localize(err)
 	}
-
-	obj.appPublicKey = publicKey
-
-	// 计算应用公钥的SN
-	cert, err := x509.ParseCertificate(blocks[0].Bytes)
+	publicKey, err := certPublicKey(cert)
 	if err != nil {
-		return err
+		return obj.Localize(err)
 	}
-	sn, err := encrypt.MD5ByStrings([]string{cert.Issuer.String(), cert.SerialNumber.String()})
+
+	// 计算应用公钥的SN
+	sn, err := certSN(cert)
 	if err != nil {
-		return err
+		return obj.Localize(err)
 	}
+
+	obj.mutex.Lock()
+	obj.appPublicKey = publicKey
 	obj.appCertPublicKeySN = sn
+	obj.mutex.Unlock()
 
 	return nil
 }
@@ -117,23 +251,15 @@ func (obj *Config) LoadAppCertPublicKey(filePath string) error {
 func (obj *Config) LoadAppPrivateKey(filePath string) error {
 	fileData, err := ioutil.ReadFile(filePath)
 	if err != nil {
-		return err
+		return obj.Localize(err)
 	}
 
 	blocks := encrypt.ParsePEMBlocks(fileData)
 	if blocks == nil {
-		return errors.New("支付宝应用私钥无效")
+		return obj.Localize(errs.Wrap(errs.ErrInvalidKey, "支付宝应用私钥无效"))
 	}
 
-	privateKey, privateKeyType, err := encrypt.ParseRSAPrivateKey(blocks[0].Bytes)
-	if err != nil {
-		return err
-	}
-
-	obj.appPrivateKey = privateKey
-	obj.appPrivateKeyType = privateKeyType
-
-	return nil
+	return obj.Localize(obj.setAppPrivateKey(blocks[0].Bytes))
 }
 
 // 设置应用私钥字符串，如果通过LoadAppPrivateKey加载了私钥文件，则不需要再用此方法设置应用私钥
@@ -142,87 +268,204 @@ func (obj *Config) SetAppPrivateKey(data string) error {
 
 	blocks := encrypt.ParsePEMBlocks(dataBytes)
 	if blocks == nil {
-		return errors.New("支付宝应用私钥无效")
+		return obj.Localize(errs.Wrap(errs.ErrInvalidKey, "支付宝应用私钥无效"))
 	}
 
-	privateKey, privateKeyType, err := encrypt.ParseRSAPrivateKey(blocks[0].Bytes)
-	if err != nil {
-		log.Println(err.Error())
-		return err
+	if err := obj.setAppPrivateKey(blocks[0].Bytes); err != nil {
+		return obj.Localize(err)
+	}
+
+	return nil
+}
+
+// 解析并设置应用私钥，先按RSA私钥解析，失败时再按PKCS8格式的SM2私钥解析，
+// 同时清除另一种类型的私钥，避免轮换密钥类型后仍使用旧的私钥签名
+func (obj *Config) setAppPrivateKey(block []byte) error {
+	privateKey, privateKeyType, err := encrypt.ParseRSAPrivateKey(block)
+	if err == nil {
+		obj.mutex.Lock()
+		obj.appPrivateKey = privateKey
+		obj.appSM2PrivateKey = nil
+		obj.appPrivateKeyType = privateKeyType
+		obj.mutex.Unlock()
+		return nil
 	}
-	obj.appPrivateKey = privateKey
-	obj.appPrivateKeyType = privateKeyType
 
+	sm2PrivateKey, sm2Err := x509.ParsePKCS8UnecryptedPrivateKey(block)
+	if sm2Err != nil {
+		return errs.Wrap(errs.ErrInvalidKey, "支付宝应用私钥无效："+err.Error())
+	}
+	obj.mutex.Lock()
+	obj.appPrivateKey = nil
+	obj.appSM2PrivateKey = sm2PrivateKey
+	obj.appPrivateKeyType = "PKCS8"
+	obj.mutex.Unlock()
 	return nil
 }
 
 // 设置应用ID
 func (obj *Config) SetAppID(value string) error {
 	if value == "" {
-		return errors.New("AppID不能为空")
+		return obj.Localize(errs.Invalid("AppID", errs.RuleRequired, "AppID不能为空"))
 	}
+	obj.mutex.Lock()
 	obj.appID = value
+	obj.mutex.Unlock()
 	return nil
 }
 
 // 设置应用签名类型
 func (obj *Config) SetAppSignType(value string) error {
-	if value != "RSA" && value != "RSA2" {
-		return errors.New("签名类型必须是RSA或RSA2(推荐)")
+	if value != "RSA" && value != "RSA2" && value != "SM2" {
+		return obj.Localize(errs.Invalid("AppSignType", errs.RuleEnum, "签名类型必须是RSA、RSA2(推荐)或SM2"))
 	}
+	obj.mutex.Lock()
 	obj.appSignType = value
+	obj.mutex.Unlock()
+	return nil
+}
+
+// 设置错误信息的语言，可以是errs.LocaleZH(默认)或errs.LocaleEN
+func (obj *Config) SetLocale(value string) error {
+	if err := errs.CheckLocale(value); err != nil {
+		return obj.Localize(err)
+	}
+	obj.mutex.Lock()
+	obj.locale = value
+	obj.mutex.Unlock()
 	return nil
 }
 
-// 获得支付宝根证书SN
+// 获得错误信息的语言，未设置时返回errs.LocaleZH
+func (obj *Config) GetLocale() string {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+	if obj.locale == "" {
+		return errs.LocaleZH
+	}
+	return obj.locale
+}
+
+// Localize 将err的错误信息翻译成配置的语言，err或配置为nil时原样返回err
+func (obj *Config) Localize(err error) error {
+	if err == nil || obj == nil {
+		return err
+	}
+	return errs.Localize(err, obj.GetLocale())
+}
+
+// 获得支付宝根证书SN，签名类型为SM2时返回SM2根证书的SN
 func (obj *Config) GetAlipayRootCertSN() string {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+	if obj.appSignType == "SM2" {
+		return obj.alipayRootCertSNSM2
+	}
 	return obj.alipayRootCertSN
 }
 
 // 获得应用公钥证书SN
 func (obj *Config) GetAppCertPublicKeySN() string {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
 	return obj.appCertPublicKeySN
 }
 
 // 获得应用签名类型
 func (obj *Config) GetAppSignType() string {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
 	return obj.appSignType
 }
 
-// 获得支付宝公钥证书
+// 获得支付宝RSA公钥，支付宝公钥证书不是RSA证书时返回nil
 func (obj *Config) GetAlipayPublicKey() *rsa.PublicKey {
-	return obj.alipayPublicKey
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+	publicKey, _ := obj.alipayPublicKey.(*rsa.PublicKey)
+	return publicKey
+}
+
+// 获得支付宝公钥证书SN
+func (obj *Config) GetAlipayCertSN() string {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+	return obj.alipayCertSN
+}
+
+// 根据证书SN获得支付宝公钥(*rsa.PublicKey或*sm2.PublicKey)，SN为空时返回当前支付宝公钥，未缓存该SN的证书时返回nil
+func (obj *Config) GetAlipayPublicKeyBySN(sn string) crypto.PublicKey {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+	if sn == "" {
+		return obj.alipayPublicKey
+	}
+	return obj.alipayPublicKeys[sn]
 }
 
 // 获得应用私钥
 func (obj *Config) GetAppPrivateKey() *rsa.PrivateKey {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
 	return obj.appPrivateKey
 }
 
+// 获得应用SM2私钥
+func (obj *Config) GetAppSM2PrivateKey() *sm2.PrivateKey {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+	return obj.appSM2PrivateKey
+}
+
 // 获得应用私钥类型(PKCS1/PKCS8)
 func (obj *Config) GetAppPrivateKeyType() string {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
 	return obj.appPrivateKeyType
 }
 
 // 获得应用ID
 func (obj *Config) GetAppID() string {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
 	return obj.appID
 }
 
-// 解析证书SN
-func parseCert(block []byte) (string, error) {
-	cert, err := x509.ParseCertificate(block)
+// 解析证书及其SN，RSA签名的证书返回sn，SM2签名的证书返回sm2SN
+func parseCert(block []byte) (cert *x509.Certificate, sn string, sm2SN string, err error) {
+	cert, err = x509.ParseCertificate(block)
 	if err != nil && err.Error() == "x509: unsupported elliptic curve" {
-		return "", nil
+		return nil, "", "", nil
 	} else if err != nil {
-		return "", err
+		return nil, "", "", errs.Wrap(errs.ErrInvalidCert, "证书格式无效："+err.Error())
+	}
+	switch cert.SignatureAlgorithm {
+	case x509.SHA256WithRSA, x509.SHA1WithRSA:
+		sn, err = certSN(cert)
+	case x509.SM2WithSM3:
+		sm2SN, err = certSN(cert)
 	}
-	if cert.SignatureAlgorithm == x509.SHA256WithRSA || cert.SignatureAlgorithm == x509.SHA1WithRSA {
-		sn, parseErr := encrypt.MD5ByStrings([]string{cert.Issuer.String(), cert.SerialNumber.String()})
-		if parseErr != nil {
-			return "", parseErr
+	if err != nil {
+		return nil, "", "", err
+	}
+	return cert, sn, sm2SN, nil
+}
+
+// 获得证书中的RSA或SM2公钥
+func certPublicKey(cert *x509.Certificate) (crypto.PublicKey, error) {
+	switch publicKey := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return publicKey, nil
+	case *ecdsa.PublicKey:
+		if publicKey.Curve != sm2.P256Sm2() {
+			break
 		}
-		return sn, nil
+		return &sm2.PublicKey{Curve: publicKey.Curve, X: publicKey.X, Y: publicKey.Y}, nil
 	}
-	return "", nil
+	return nil, errs.Wrap(errs.ErrInvalidCert, "仅支持RSA和SM2公钥证书")
+}
+
+// 计算证书SN，即签发者与序列号拼接后的MD5值
+func certSN(cert *x509.Certificate) (string, error) {
+	return encrypt.MD5ByStrings([]string{cert.Issuer.String(), cert.SerialNumber.String()})
 }
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/dxvgef/alipay/errs"
+)
+
+// Registry 按AppID索引的多商户配置注册表，用于服务商/平台同时管理多个商户的配置，可并发使用。
+// 配置的AppID在注册后通过SetAppID、Update等方式修改时，注册表按修改后的AppID查找配置
+type Registry struct {
+	mutex   sync.RWMutex
+	configs map[string]*Config
+	locale  string // 错误信息的语言
+}
+
+// NewRegistry 创建一个空的配置注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		configs: make(map[string]*Config),
+	}
+}
+
+// Register 注册配置，以配置的AppID作为键，已存在相同AppID的配置时将被替换
+func (r *Registry) Register(alipayConfig *Config) error {
+	if alipayConfig == nil {
+		return r.localize(errors.New("支付宝配置不能为nil"))
+	}
+	appID := alipayConfig.GetAppID()
+	if appID == "" {
+		return alipayConfig.Localize(errors.New("未设置支付宝配置的AppID参数值"))
+	}
+	r.mutex.Lock()
+	r.reindex()
+	r.configs[appID] = alipayConfig
+	r.mutex.Unlock()
+	return nil
+}
+
+// Unregister 注销指定AppID的配置
+func (r *Registry) Unregister(appID string) {
+	r.mutex.Lock()
+	r.reindex()
+	delete(r.configs, appID)
+	r.mutex.Unlock()
+}
+
+// Get 获得指定AppID的配置
+func (r *Registry) Get(appID string) (*Config, error) {
+	if appID == "" {
+		return nil, r.localize(errors.New("AppID不能为空"))
+	}
+	r.mutex.RLock()
+	alipayConfig, exists := r.configs[appID]
+	r.mutex.RUnlock()
+	// 注册后修改了AppID的配置不能再用原来的AppID找到，重建索引后再查找
+	if !exists || alipayConfig.GetAppID() != appID {
+		r.mutex.Lock()
+		r.reindex()
+		alipayConfig, exists = r.configs[appID]
+		r.mutex.Unlock()
+	}
+	if !exists {
+		return nil, r.localize(errors.New("未注册AppID为" + appID + "的支付宝配置"))
+	}
+	return alipayConfig, nil
+}
+
+// AppIDs 获得所有已注册配置的AppID，按字典序排列
+func (r *Registry) AppIDs() []string {
+	r.mutex.Lock()
+	r.reindex()
+	appIDs := make([]string, 0, len(r.configs))
+	for appID := range r.configs {
+		appIDs = append(appIDs, appID)
+	}
+	r.mutex.Unlock()
+	sort.Strings(appIDs)
+	return appIDs
+}
+
+// 按配置当前的AppID重建索引，多个配置被修改成相同的AppID时保留原来以该AppID注册的配置，必须在持有写锁时调用
+func (r *Registry) reindex() {
+	configs := make(map[string]*Config, len(r.configs))
+	for key, alipayConfig := range r.configs {
+		appID := alipayConfig.GetAppID()
+		if appID == "" {
+			continue
+		}
+		if _, exists := configs[appID]; exists && key != appID {
+			continue
+		}
+		configs[appID] = alipayConfig
+	}
+	r.configs = configs
+}
+
+// SetLocale 设置注册表错误信息的语言，可以是errs.LocaleZH(默认)或errs.LocaleEN
+func (r *Registry) SetLocale(value string) error {
+	if err := errs.CheckLocale(value); err != nil {
+		return errs.Localize(err, r.getLocale())
+	}
+	r.mutex.Lock()
+	r.locale = value
+	r.mutex.Unlock()
+	return nil
+}
+
+// 获得错误信息的语言
+func (r *Registry) getLocale() string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.locale
+}
+
+// 将err的错误信息翻译成注册表设置的语言
+func (r *Registry) localize(err error) error {
+	return errs.Localize(err, r.getLocale())
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 创建指定AppID的配置
+func newTestConfig(t *testing.T, appID string) *Config {
+	var alipayConfig Config
+	if err := alipayConfig.SetAppID(appID); err != nil {
+		t.Fatal(err)
+	}
+	return &alipayConfig
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	first := newTestConfig(t, "2021000000000001")
+	second := newTestConfig(t, "2021000000000002")
+	for _, alipayConfig := range []*Config{first, second} {
+		if err := registry.Register(alipayConfig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if alipayConfig, err := registry.Get("2021000000000002"); err != nil || alipayConfig != second {
+		t.Fatalf("期望得到第二个配置，得到%p，%v", alipayConfig, err)
+	}
+	if expected := []string{"2021000000000001", "2021000000000002"}; !reflect.DeepEqual(registry.AppIDs(), expected) {
+		t.Fatalf("期望AppID列表%q，得到%q", expected, registry.AppIDs())
+	}
+
+	registry.Unregister("2021000000000001")
+	if _, err := registry.Get("2021000000000001"); err == nil {
+		t.Fatal("注销后的配置不应被找到")
+	}
+
+	for _, alipayConfig := range []*Config{nil, {}} {
+		if err := registry.Register(alipayConfig); err == nil {
+			t.Fatal("注册nil或未设置AppID的配置时应返回错误")
+		}
+	}
+	if _, err := registry.Get(""); err == nil {
+		t.Fatal("AppID为空时应返回错误")
+	}
+}
+
+func TestRegistryAppIDChanged(t *testing.T) {
+	registry := NewRegistry()
+	alipayConfig := newTestConfig(t, "2021000000000001")
+	if err := registry.Register(alipayConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	// 通过Update修改AppID后只能用新的AppID找到配置
+	if err := alipayConfig.Update(func(c *Config) error {
+		return c.SetAppID("2021000000000009")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Get("2021000000000001"); err == nil {
+		t.Fatal("不应再用修改前的AppID找到配置")
+	}
+	if found, err := registry.Get("2021000000000009"); err != nil || found != alipayConfig {
+		t.Fatalf("期望用修改后的AppID找到配置，得到%p，%v", found, err)
+	}
+	if expected := []string{"2021000000000009"}; !reflect.DeepEqual(registry.AppIDs(), expected) {
+		t.Fatalf("期望AppID列表%q，得到%q", expected, registry.AppIDs())
+	}
+
+	// 注册使用修改前AppID的新配置，不影响已修改AppID的配置
+	other := newTestConfig(t, "2021000000000001")
+	if err := registry.Register(other); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := registry.Get("2021000000000001"); err != nil || found != other {
+		t.Fatalf("期望找到新注册的配置，得到%p，%v", found, err)
+	}
+	if found, err := registry.Get("2021000000000009"); err != nil || found != alipayConfig {
+		t.Fatalf("期望仍能找到已修改AppID的配置，得到%p，%v", found, err)
+	}
+}
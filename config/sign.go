@@ -0,0 +1,159 @@
+package config
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+	"sort"
+
+	"github.com/dxvgef/alipay/errs"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// Signer 应用签名器，与crypto.Signer兼容，可以将签名委托给PKCS#11、云KMS或远程签名服务，使私钥不离开其安全边界。
+// 签名类型为RSA/RSA2时，Sign方法收到的digest是已按签名类型计算好的摘要，opts.HashFunc()返回对应的哈希算法；
+// 签名类型为SM2时，由于SM3摘要需要包含公钥信息，Sign方法收到的是原始待签名数据，opts.HashFunc()返回0，签名结果须为ASN.1编码
+type Signer interface {
+	Public() crypto.PublicKey
+	Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error)
+}
+
+// 设置应用签名器，设置后将优先于应用私钥用于生成签名
+func (obj *Config) SetAppSigner(signer Signer) error {
+	if signer == nil {
+		return obj.Localize(errors.New("应用签名器不能为nil"))
+	}
+	obj.mutex.Lock()
+	obj.appSigner = signer
+	obj.mutex.Unlock()
+	return nil
+}
+
+// 获得应用签名器，未设置签名器时返回由应用私钥实现的默认签名器，两者都未设置时返回nil
+func (obj *Config) GetAppSigner() Signer {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+	if obj.appSigner != nil {
+		return obj.appSigner
+	}
+	if obj.appSignType == "SM2" {
+		if obj.appSM2PrivateKey != nil {
+			return obj.appSM2PrivateKey
+		}
+		return nil
+	}
+	if obj.appPrivateKey != nil {
+		return obj.appPrivateKey
+	}
+	return nil
+}
+
+// Sign 按应用签名类型使用应用签名器对data生成Base64编码的签名
+func (obj *Config) Sign(data []byte) (string, error) {
+	signer := obj.GetAppSigner()
+	if signer == nil {
+		return "", errors.New("未设置支付宝配置的应用私钥或签名器")
+	}
+
+	var digest []byte
+	var opts crypto.SignerOpts
+	if obj.GetAppSignType() == "SM2" {
+		digest = data
+		opts = crypto.Hash(0)
+	} else {
+		h, hType, err := signHash(obj.GetAppSignType())
+		if err != nil {
+			return "", err
+		}
+		if _, err = h.Write(data); err != nil {
+			return "", err
+		}
+		digest = h.Sum(nil)
+		opts = hType
+	}
+
+	signBytes, err := signer.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signBytes), nil
+}
+
+// VerifySign 使用SN对应的支付宝公钥校验Base64编码的签名，SN为空时使用当前支付宝公钥
+func (obj *Config) VerifySign(data []byte, sign string, sn string) error {
+	publicKey := obj.GetAlipayPublicKeyBySN(sn)
+	if publicKey == nil {
+		return errs.Wrap(errs.ErrCertNotFound, "无法获得SN为"+sn+"的支付宝公钥证书")
+	}
+
+	signData, err := base64.StdEncoding.DecodeString(sign)
+	if err != nil {
+		return errs.Wrap(errs.ErrSignMismatch, "签名不是有效的Base64编码")
+	}
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		h, hType, err := signHash(obj.GetAppSignType())
+		if err != nil {
+			return err
+		}
+		if _, err = h.Write(data); err != nil {
+			return err
+		}
+		if rsa.VerifyPKCS1v15(key, hType, h.Sum(nil), signData) != nil {
+			return errs.ErrSignMismatch
+		}
+	case *sm2.PublicKey:
+		if !key.Verify(data, signData) {
+			return errs.ErrSignMismatch
+		}
+	default:
+		return errors.New("不支持的支付宝公钥类型")
+	}
+	return nil
+}
+
+// VerifySignByAnyKey 依次使用当前支付宝公钥和所有缓存的支付宝公钥校验签名，任意一个通过即返回nil，
+// 用于不携带证书SN的异步通知，支付宝轮换证书后新旧证书签名的通知都能通过校验
+func (obj *Config) VerifySignByAnyKey(data []byte, sign string) error {
+	err := obj.VerifySign(data, sign, "")
+	if err == nil {
+		return nil
+	}
+	for _, sn := range obj.getAlipayCertSNs() {
+		if obj.VerifySign(data, sign, sn) == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// 获得所有缓存的支付宝公钥证书SN
+func (obj *Config) getAlipayCertSNs() []string {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+	sns := make([]string, 0, len(obj.alipayPublicKeys))
+	for sn := range obj.alipayPublicKeys {
+		sns = append(sns, sn)
+	}
+	sort.Strings(sns)
+	return sns
+}
+
+// 获得RSA签名类型对应的哈希算法
+func signHash(signType string) (hash.Hash, crypto.Hash, error) {
+	switch signType {
+	case "RSA":
+		return sha1.New(), crypto.SHA1, nil
+	case "RSA2":
+		return sha256.New(), crypto.SHA256, nil
+	default:
+		return nil, 0, errors.New("仅支持RSA(SHA1)、RSA2(SHA256)和SM2(SM3)三种签名算法")
+	}
+}
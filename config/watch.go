@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// WatchFiles 需要监视的证书和密钥文件路径，为空的路径不会被监视
+type WatchFiles struct {
+	AlipayRootCert      string // 支付宝根证书文件路径
+	AlipayCertPublicKey string // 支付宝公钥证书文件路径
+	AppCertPublicKey    string // 应用公钥证书文件路径
+	AppPrivateKey       string // 应用私钥文件路径
+}
+
+// Watcher 证书和密钥文件监视器
+type Watcher struct {
+	config   *Config
+	files    WatchFiles
+	interval time.Duration
+	onError  func(error)
+	stamps   map[string]fileStamp
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// 文件的修改时间和大小
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch 按interval的间隔检查文件，当任意文件发生变化时重新加载所有文件并原子地替换密钥材料。
+// 重新加载失败时会调用onError(可以为nil)，并继续使用最后一次成功加载的密钥材料
+func (obj *Config) Watch(files WatchFiles, interval time.Duration, onError func(error)) (*Watcher, error) {
+	if interval <= 0 {
+		return nil, obj.Localize(errors.New("文件检查间隔必须大于0"))
+	}
+	w := &Watcher{
+		config:   obj,
+		files:    files,
+		interval: interval,
+		onError:  onError,
+		stop:     make(chan struct{}),
+	}
+	stamps, err := w.stat()
+	if err != nil {
+		return nil, obj.Localize(err)
+	}
+	if len(stamps) == 0 {
+		return nil, obj.Localize(errors.New("未指定需要监视的文件"))
+	}
+	w.stamps = stamps
+
+	go w.run()
+	return w, nil
+}
+
+// Stop 停止监视
+func (w *Watcher) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+}
+
+// 定时检查文件变化
+func (w *Watcher) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// 检查文件是否变化，变化时重新加载
+func (w *Watcher) check() {
+	stamps, err := w.stat()
+	if err != nil {
+		w.report(err)
+		return
+	}
+	changed := false
+	for path := range stamps {
+		if stamps[path] != w.stamps[path] {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return
+	}
+	// 无论加载是否成功都记录本次的文件状态，避免对同一个无效文件重复报错
+	w.stamps = stamps
+	if err = w.config.Update(w.reload); err != nil {
+		w.report(err)
+	}
+}
+
+// 在配置副本上重新加载所有文件
+func (w *Watcher) reload(c *Config) error {
+	if w.files.AlipayRootCert != "" {
+		if err := c.LoadAlipayRootCert(w.files.AlipayRootCert); err != nil {
+			return err
+		}
+	}
+	if w.files.AlipayCertPublicKey != "" {
+		if err := c.LoadAlipayCertPublicKey(w.files.AlipayCertPublicKey); err != nil {
+			return err
+		}
+	}
+	if w.files.AppCertPublicKey != "" {
+		if err := c.LoadAppCertPublicKey(w.files.AppCertPublicKey); err != nil {
+			return err
+		}
+	}
+	if w.files.AppPrivateKey != "" {
+		if err := c.LoadAppPrivateKey(w.files.AppPrivateKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 获得所有文件的状态
+func (w *Watcher) stat() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+	for _, path := range []string{w.files.AlipayRootCert, w.files.AlipayCertPublicKey, w.files.AppCertPublicKey, w.files.AppPrivateKey} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
+
+// 报告重新加载失败
+func (w *Watcher) report(err error) {
+	if w.onError != nil {
+		w.onError(w.config.Localize(err))
+	}
+}
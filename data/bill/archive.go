@@ -0,0 +1,130 @@
+package bill
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 账单ZIP中明细文件的文件名后缀
+const (
+	tradeDetailSuffix   = "业务明细.csv"
+	accountDetailSuffix = "账务明细.csv"
+)
+
+// Archive 支付宝对账单ZIP文件
+type Archive struct {
+	files  []*zip.File
+	closer io.Closer
+}
+
+// OpenArchive 打开本地的对账单ZIP文件
+func OpenArchive(filePath string) (*Archive, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{
+		files:  reader.File,
+		closer: reader,
+	}, nil
+}
+
+// NewArchive 从内存或其它io.ReaderAt中读取对账单ZIP文件
+func NewArchive(r io.ReaderAt, size int64) (*Archive, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{
+		files: reader.File,
+	}, nil
+}
+
+// Close 关闭通过OpenArchive打开的文件
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// FileNames 获得ZIP中所有文件的文件名，GBK编码的文件名会被转换成UTF-8
+func (a *Archive) FileNames() []string {
+	names := make([]string, len(a.files))
+	for k := range a.files {
+		names[k] = fileName(a.files[k])
+	}
+	return names
+}
+
+// EachTrade 逐行读取业务明细文件，fn返回错误时停止读取并返回该错误
+func (a *Archive) EachTrade(fn func(*Reader, *TradeRow) error) error {
+	return a.each(tradeDetailSuffix, func(r *Reader) error {
+		for {
+			row, err := r.ReadTrade()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err = fn(r, row); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// EachAccount 逐行读取账务明细文件，fn返回错误时停止读取并返回该错误
+func (a *Archive) EachAccount(fn func(*Reader, *AccountRow) error) error {
+	return a.each(accountDetailSuffix, func(r *Reader) error {
+		for {
+			row, err := r.ReadAccount()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err = fn(r, row); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// 找到指定后缀的明细文件并读取
+func (a *Archive) each(suffix string, fn func(*Reader) error) error {
+	for k := range a.files {
+		name := fileName(a.files[k])
+		// 跳过文件名中带有"汇总"的汇总文件
+		if !strings.HasSuffix(name, suffix) || strings.Contains(name, "汇总") {
+			continue
+		}
+		rc, err := a.files[k].Open()
+		if err != nil {
+			return err
+		}
+		err = fn(NewReader(rc))
+		rc.Close()
+		return err
+	}
+	return errors.New("对账单中不存在" + suffix + "文件")
+}
+
+// 获得文件名，非UTF-8编码的文件名按GBK解码
+func fileName(file *zip.File) string {
+	if !file.NonUTF8 {
+		return file.Name
+	}
+	name, err := ioutil.ReadAll(simplifiedchinese.GBK.NewDecoder().Reader(strings.NewReader(file.Name)))
+	if err != nil {
+		return file.Name
+	}
+	return string(name)
+}
@@ -0,0 +1,111 @@
+package bill
+
+//go:generate go run testdata/gen.go
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArchiveFileNames(t *testing.T) {
+	archive, err := OpenArchive("testdata/20190801.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	expected := []string{
+		"20881234567890120156_20190801_业务明细(汇总).csv",
+		"20881234567890120156_20190801_业务明细.csv",
+		"20881234567890120156_20190801_账务明细(汇总).csv",
+		"20881234567890120156_20190801_账务明细.csv",
+	}
+	if names := archive.FileNames(); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("期望文件名%q，得到%q", expected, names)
+	}
+}
+
+func TestArchiveEachTrade(t *testing.T) {
+	archive, err := OpenArchive("testdata/20190801.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	var rows []*TradeRow
+	var reader *Reader
+	if err = archive.EachTrade(func(r *Reader, row *TradeRow) error {
+		reader = r
+		rows = append(rows, row)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// 汇总文件在明细文件之前，读到的是汇总文件时行数和列都不对
+	if len(rows) != 3 {
+		t.Fatalf("期望3行业务明细，得到%d行", len(rows))
+	}
+	if rows[0].TradeNo != "2019080122001400001" || rows[0].OutTradeNo != "ORDER001" || rows[0].BizType != "交易" {
+		t.Errorf("第1行的单号或业务类型错误：%+v", rows[0])
+	}
+	if rows[0].TotalAmount != 10 || rows[0].ServiceFee != -0.06 || rows[0].BuyerAccount != "abc***@example.com" {
+		t.Errorf("第1行的金额或对方账户错误：%+v", rows[0])
+	}
+	if rows[1].BizType != "退款" || rows[1].TotalAmount != -2.5 || rows[1].RefundBatchNo != "REFUND001" {
+		t.Errorf("第2行的退款信息错误：%+v", rows[1])
+	}
+	if rows[2].Subject != "测试商品二，含逗号" || rows[2].StoreName != "测试门店" || rows[2].AlipayRedPacket != 1 || rows[2].Remark != "备注" {
+		t.Errorf("第3行的商品、门店、红包或备注错误：%+v", rows[2])
+	}
+
+	if header := reader.Header(); len(header) != 4 || !strings.HasPrefix(header[0], "支付宝业务明细查询") {
+		t.Errorf("说明行错误：%q", header)
+	}
+	if footer := reader.Footer(); len(footer) != 4 || !strings.HasPrefix(footer[3], "导出时间") {
+		t.Errorf("汇总行错误：%q", footer)
+	}
+}
+
+func TestArchiveEachAccount(t *testing.T) {
+	archive, err := OpenArchive("testdata/20190801.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	var rows []*AccountRow
+	if err = archive.EachAccount(func(r *Reader, row *AccountRow) error {
+		rows = append(rows, row)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("期望3行账务明细，得到%d行", len(rows))
+	}
+	if rows[0].AccountLogID != "20190801001" || rows[0].TradeNo != "2019080122001400001" || rows[0].IncomeAmount != 10 || rows[0].Balance != 110 {
+		t.Errorf("第1行错误：%+v", rows[0])
+	}
+	if rows[1].BizType != "收费" || rows[1].OutcomeAmount != -0.06 || rows[1].OtherAccount != "支付宝（中国）网络技术有限公司" {
+		t.Errorf("第2行错误：%+v", rows[1])
+	}
+	if rows[2].BizType != "交易退款" || rows[2].OutcomeAmount != -2.5 || rows[2].Balance != 107.44 {
+		t.Errorf("第3行错误：%+v", rows[2])
+	}
+}
+
+func TestArchiveMissingFile(t *testing.T) {
+	archive, err := OpenArchive("testdata/trade_only.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	err = archive.EachAccount(func(*Reader, *AccountRow) error {
+		return nil
+	})
+	if err == nil || err.Error() != "对账单中不存在账务明细.csv文件" {
+		t.Fatalf("期望缺少账务明细文件的错误，得到%v", err)
+	}
+}
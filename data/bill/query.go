@@ -0,0 +1,69 @@
+package bill
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
+	"github.com/dxvgef/alipay/gateway"
+)
+
+// 查询对账单下载地址的接口名称
+const downloadURLMethod = "alipay.data.dataservice.bill.downloadurl.query"
+
+// 账单类型
+const (
+	TypeTrade        = "trade"        // 商户基于支付宝交易收单的业务账单
+	TypeSignCustomer = "signcustomer" // 基于商户支付宝余额收入及支出等资金变动的账务账单
+)
+
+// 接口响应参数
+type downloadURLResponse struct {
+	gateway.Response
+	BillDownloadURL string `json:"bill_download_url"` // 账单下载地址，有效时间为30秒
+}
+
+// QueryDownloadURL 查询对账单下载地址，billDate为日账单格式yyyy-MM-dd或月账单格式yyyy-MM
+func QueryDownloadURL(alipayConfig *config.Config, billType, billDate string) (string, error) {
+	if billType != TypeTrade && billType != TypeSignCustomer {
+		return "", alipayConfig.Localize(errs.Invalid("billType", errs.RuleEnum, "账单类型只能是trade或signcustomer"))
+	}
+	if _, err := time.Parse("2006-01-02", billDate); err != nil {
+		if _, err = time.Parse("2006-01", billDate); err != nil {
+			return "", alipayConfig.Localize(errs.Invalid("billDate", errs.RuleFormat, "账单时间的格式必须是yyyy-MM-dd或yyyy-MM"))
+		}
+	}
+
+	var resp downloadURLResponse
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method: downloadURLMethod,
+		BizContent: map[string]string{
+			"bill_type": billType,
+			"bill_date": billDate,
+		},
+	}, &resp); err != nil {
+		return "", err
+	}
+	if resp.BillDownloadURL == "" {
+		return "", alipayConfig.Localize(errors.New("支付宝未返回账单下载地址"))
+	}
+	return resp.BillDownloadURL, nil
+}
+
+// Download 下载对账单ZIP文件并写入到w，下载地址的有效时间为30秒，获得地址后应立即下载，
+// 错误信息使用alipayConfig设置的语言
+func Download(alipayConfig *config.Config, billDownloadURL string, w io.Writer) error {
+	resp, err := gateway.HTTPClient.Get(billDownloadURL)
+	if err != nil {
+		return alipayConfig.Localize(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return alipayConfig.Localize(errors.New("账单下载失败：" + resp.Status))
+	}
+	_, err = io.Copy(w, resp.Body)
+	return alipayConfig.Localize(err)
+}
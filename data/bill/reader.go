@@ -0,0 +1,236 @@
+package bill
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// TradeRow 业务明细(trade账单)中的一行
+type TradeRow struct {
+	TradeNo           string  // 支付宝交易号
+	OutTradeNo        string  // 商户订单号
+	BizType           string  // 业务类型，如交易、退款
+	Subject           string  // 商品名称
+	CreateTime        string  // 创建时间，格式为yyyy-MM-dd HH:mm:ss
+	FinishTime        string  // 完成时间，格式为yyyy-MM-dd HH:mm:ss
+	StoreID           string  // 门店编号
+	StoreName         string  // 门店名称
+	Operator          string  // 操作员
+	TerminalID        string  // 终端号
+	BuyerAccount      string  // 对方账户
+	TotalAmount       float64 // 订单金额，单位为元，退款时为负数
+	ReceiptAmount     float64 // 商家实收，单位为元
+	AlipayRedPacket   float64 // 支付宝红包，单位为元
+	PointAmount       float64 // 集分宝，单位为元
+	AlipayDiscount    float64 // 支付宝优惠，单位为元
+	MerchantDiscount  float64 // 商家优惠，单位为元
+	VoucherAmount     float64 // 券核销金额，单位为元
+	VoucherName       string  // 券名称
+	MerchantRedPacket float64 // 商家红包消费金额，单位为元
+	CardAmount        float64 // 卡消费金额，单位为元
+	RefundBatchNo     string  // 退款批次号/请求号
+	ServiceFee        float64 // 服务费，单位为元
+	RoyaltyAmount     float64 // 分润，单位为元
+	Remark            string  // 备注
+}
+
+// AccountRow 账务明细(signcustomer账单)中的一行
+type AccountRow struct {
+	AccountLogID  string  // 账务流水号
+	TradeNo       string  // 业务流水号
+	OutTradeNo    string  // 商户订单号
+	Subject       string  // 商品名称
+	TransTime     string  // 发生时间，格式为yyyy-MM-dd HH:mm:ss
+	OtherAccount  string  // 对方账号
+	IncomeAmount  float64 // 收入金额，单位为元
+	OutcomeAmount float64 // 支出金额，单位为元，为负数
+	Balance       float64 // 账户余额，单位为元
+	Channel       string  // 交易渠道
+	BizType       string  // 业务类型
+	Remark        string  // 备注
+}
+
+// Reader 对账单CSV文件读取器，以流的方式逐行读取GBK编码的账单明细
+type Reader struct {
+	scanner *bufio.Scanner
+	columns map[string]int // 列名与列序号的映射
+	header  []string       // 明细之前以#开头的说明行
+	footer  []string       // 明细之后以#开头的汇总行
+}
+
+// NewReader 创建对账单CSV文件读取器，r为支付宝对账单ZIP中的GBK编码的CSV文件内容
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(transform.NewReader(r, simplifiedchinese.GBK.NewDecoder()))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &Reader{
+		scanner: scanner,
+	}
+}
+
+// Header 获得明细之前的说明行，如账号、起始日期等，已去除开头的#
+func (r *Reader) Header() []string {
+	return r.header
+}
+
+// Footer 获得明细之后的汇总行，如交易合计、导出时间等，已去除开头的#，读取到io.EOF之后才完整
+func (r *Reader) Footer() []string {
+	return r.footer
+}
+
+// ReadTrade 读取业务明细的下一行，没有更多数据时返回io.EOF
+func (r *Reader) ReadTrade() (*TradeRow, error) {
+	record, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	row := &TradeRow{
+		TradeNo:       r.get(record, "支付宝交易号"),
+		OutTradeNo:    r.get(record, "商户订单号"),
+		BizType:       r.get(record, "业务类型"),
+		Subject:       r.get(record, "商品名称"),
+		CreateTime:    r.get(record, "创建时间"),
+		FinishTime:    r.get(record, "完成时间"),
+		StoreID:       r.get(record, "门店编号"),
+		StoreName:     r.get(record, "门店名称"),
+		Operator:      r.get(record, "操作员"),
+		TerminalID:    r.get(record, "终端号"),
+		BuyerAccount:  r.get(record, "对方账户"),
+		VoucherName:   r.get(record, "券名称"),
+		RefundBatchNo: r.get(record, "退款批次号/请求号"),
+		Remark:        r.get(record, "备注"),
+	}
+	amounts := []struct {
+		column string
+		value  *float64
+	}{
+		{"订单金额（元）", &row.TotalAmount},
+		{"商家实收（元）", &row.ReceiptAmount},
+		{"支付宝红包（元）", &row.AlipayRedPacket},
+		{"集分宝（元）", &row.PointAmount},
+		{"支付宝优惠（元）", &row.AlipayDiscount},
+		{"商家优惠（元）", &row.MerchantDiscount},
+		{"券核销金额（元）", &row.VoucherAmount},
+		{"商家红包消费金额（元）", &row.MerchantRedPacket},
+		{"卡消费金额（元）", &row.CardAmount},
+		{"服务费（元）", &row.ServiceFee},
+		{"分润（元）", &row.RoyaltyAmount},
+	}
+	for k := range amounts {
+		if *amounts[k].value, err = r.getAmount(record, amounts[k].column); err != nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
+
+// ReadAccount 读取账务明细的下一行，没有更多数据时返回io.EOF
+func (r *Reader) ReadAccount() (*AccountRow, error) {
+	record, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	row := &AccountRow{
+		AccountLogID: r.get(record, "账务流水号"),
+		TradeNo:      r.get(record, "业务流水号"),
+		OutTradeNo:   r.get(record, "商户订单号"),
+		Subject:      r.get(record, "商品名称"),
+		TransTime:    r.get(record, "发生时间"),
+		OtherAccount: r.get(record, "对方账号"),
+		Channel:      r.get(record, "交易渠道"),
+		BizType:      r.get(record, "业务类型"),
+		Remark:       r.get(record, "备注"),
+	}
+	if row.IncomeAmount, err = r.getAmount(record, "收入金额（+元）"); err != nil {
+		return nil, err
+	}
+	if row.OutcomeAmount, err = r.getAmount(record, "支出金额（-元）"); err != nil {
+		return nil, err
+	}
+	if row.Balance, err = r.getAmount(record, "账户余额（元）"); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// 读取下一行明细，跳过说明行、汇总行和列名行
+func (r *Reader) next() ([]string, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			line = strings.TrimLeft(line, "#")
+			if r.columns == nil {
+				r.header = append(r.header, line)
+			} else {
+				r.footer = append(r.footer, line)
+			}
+			continue
+		}
+
+		record, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+		if r.columns == nil {
+			r.columns = make(map[string]int, len(record))
+			for k := range record {
+				r.columns[record[k]] = k
+			}
+			continue
+		}
+		return record, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if r.columns == nil {
+		return nil, errors.New("对账单中缺少列名行")
+	}
+	return nil, io.EOF
+}
+
+// 获得指定列的值
+func (r *Reader) get(record []string, column string) string {
+	index, exists := r.columns[column]
+	if !exists || index >= len(record) {
+		return ""
+	}
+	return record[index]
+}
+
+// 获得指定列的金额
+func (r *Reader) getAmount(record []string, column string) (float64, error) {
+	value := r.get(record, column)
+	if value == "" {
+		return 0, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.New("对账单中" + column + "列的金额格式无效：" + value)
+	}
+	return amount, nil
+}
+
+// 解析一行CSV，去除各列首尾的空白和制表符
+func parseLine(line string) ([]string, error) {
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	record, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	for k := range record {
+		record[k] = strings.TrimSpace(record[k])
+	}
+	return record, nil
+}
@@ -0,0 +1,80 @@
+package bill
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 将UTF-8内容按GBK编码，模拟支付宝对账单中的CSV文件
+func gbkReader(t *testing.T, content string) *Reader {
+	data, err := simplifiedchinese.GBK.NewEncoder().String(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewReader(strings.NewReader(data))
+}
+
+func TestReaderHeaderFooter(t *testing.T) {
+	reader := gbkReader(t, "#支付宝账务明细查询\r\n#账号：[2088]\r\n\r\n"+
+		"账务流水号,业务流水号,收入金额（+元）,支出金额（-元）,账户余额（元）\r\n"+
+		"001\t,002\t,1.00,0.00,1.00\r\n"+
+		"\r\n"+
+		"#收入合计：1笔\r\n#导出时间：[2019年08月02日 10:29:43]\r\n")
+
+	row, err := reader.ReadAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.AccountLogID != "001" || row.TradeNo != "002" || row.IncomeAmount != 1 || row.Balance != 1 {
+		t.Errorf("明细行错误：%+v", row)
+	}
+	// 不存在的列为空值
+	if row.OutTradeNo != "" || row.Remark != "" {
+		t.Errorf("不存在的列应为空：%+v", row)
+	}
+	if _, err = reader.ReadAccount(); err != io.EOF {
+		t.Fatalf("期望io.EOF，得到%v", err)
+	}
+
+	if expected := []string{"支付宝账务明细查询", "账号：[2088]"}; !reflect.DeepEqual(reader.Header(), expected) {
+		t.Errorf("期望说明行%q，得到%q", expected, reader.Header())
+	}
+	if expected := []string{"收入合计：1笔", "导出时间：[2019年08月02日 10:29:43]"}; !reflect.DeepEqual(reader.Footer(), expected) {
+		t.Errorf("期望汇总行%q，得到%q", expected, reader.Footer())
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"空文件", "", "对账单中缺少列名行"},
+		{"只有说明行", "#支付宝业务明细查询\n#账号：[2088]\n", "对账单中缺少列名行"},
+		{"金额格式无效", "支付宝交易号,订单金额（元）\n001,abc\n", "对账单中订单金额（元）列的金额格式无效：abc"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := gbkReader(t, c.content).ReadTrade()
+			if err == nil || err.Error() != c.message {
+				t.Fatalf("期望错误%q，得到%v", c.message, err)
+			}
+		})
+	}
+}
+
+func TestReaderEmptyAmount(t *testing.T) {
+	reader := gbkReader(t, "支付宝交易号,订单金额（元）,服务费（元）\n001,,\n")
+	row, err := reader.ReadTrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.TradeNo != "001" || row.TotalAmount != 0 || row.ServiceFee != 0 {
+		t.Errorf("空金额应为0：%+v", row)
+	}
+}
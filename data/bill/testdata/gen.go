@@ -0,0 +1,121 @@
+// +build ignore
+
+// 生成测试用的对账单ZIP文件，文件名和内容都按GBK编码，与支付宝下载的对账单格式一致，
+// 在data/bill目录中执行go generate重新生成
+package main
+
+import (
+	"archive/zip"
+	"log"
+	"os"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 业务明细
+const tradeDetail = `#支付宝业务明细查询
+#账号：[20881234567890120156]
+#起始日期：[2019年08月01日 00:00:00]   终止日期：[2019年08月02日 00:00:00]
+#-----------------------------------------业务明细列表----------------------------------------
+支付宝交易号,商户订单号,业务类型,商品名称,创建时间,完成时间,门店编号,门店名称,操作员,终端号,对方账户,订单金额（元）,商家实收（元）,支付宝红包（元）,集分宝（元）,支付宝优惠（元）,商家优惠（元）,券核销金额（元）,券名称,商家红包消费金额（元）,卡消费金额（元）,退款批次号/请求号,服务费（元）,分润（元）,备注
+2019080122001400001	,ORDER001	,交易,测试商品一,2019-08-01 10:00:00,2019-08-01 10:00:05,,,,,abc***@example.com,10.00,10.00,0.00,0.00,0.00,0.00,0.00,,0.00,0.00,,-0.06,0.00,
+2019080122001400001	,ORDER001	,退款,测试商品一,2019-08-01 11:00:00,2019-08-01 11:00:05,,,,,abc***@example.com,-2.50,-2.50,0.00,0.00,0.00,0.00,0.00,,0.00,0.00,REFUND001,0.01,0.00,
+2019080122001400002	,ORDER002	,交易,"测试商品二，含逗号",2019-08-01 12:00:00,2019-08-01 12:00:09,S01,测试门店,,,def***@example.com,25.80,24.80,1.00,0.00,0.00,0.00,0.00,,0.00,0.00,,-0.15,0.00,备注
+#-----------------------------------------业务明细列表结束------------------------------------
+#交易合计：2笔，商家实收共34.80元，商家优惠共0.00元
+#退款合计：1笔，商家实收退款共-2.50元，商家优惠退款共0.00元
+#导出时间：[2019年08月02日 10:29:43]
+`
+
+// 业务明细汇总
+const tradeSummary = `#支付宝业务汇总查询
+#账号：[20881234567890120156]
+#起始日期：[2019年08月01日 00:00:00]   终止日期：[2019年08月02日 00:00:00]
+#-----------------------------------------业务汇总列表----------------------------------------
+门店编号,门店名称,交易订单总笔数,退款订单总笔数,订单金额（元）,商家实收（元）
+,,2,1,33.30,32.30
+#-----------------------------------------业务汇总列表结束------------------------------------
+#导出时间：[2019年08月02日 10:29:43]
+`
+
+// 账务明细
+const accountDetail = `#支付宝账务明细查询
+#账号：[20881234567890120156]
+#起始日期：[2019年08月01日 00:00:00]   终止日期：[2019年08月02日 00:00:00]
+#-----------------------------------------账务明细列表----------------------------------------
+账务流水号,业务流水号,商户订单号,商品名称,发生时间,对方账号,收入金额（+元）,支出金额（-元）,账户余额（元）,交易渠道,业务类型,备注
+20190801001	,2019080122001400001	,ORDER001	,测试商品一,2019-08-01 10:00:05,abc***@example.com,10.00,0.00,110.00,支付宝,在线支付,
+20190801002	,2019080122001400001	,ORDER001	,测试商品一,2019-08-01 10:00:05,支付宝（中国）网络技术有限公司,0.00,-0.06,109.94,支付宝,收费,
+20190801003	,2019080122001400001	,ORDER001	,测试商品一,2019-08-01 11:00:05,abc***@example.com,0.00,-2.50,107.44,支付宝,交易退款,
+#-----------------------------------------账务明细列表结束------------------------------------
+#支出合计：2笔，共-2.56元
+#收入合计：1笔，共10.00元
+#导出时间：[2019年08月02日 10:29:43]
+`
+
+// 账务明细汇总
+const accountSummary = `#支付宝账务汇总查询
+#账号：[20881234567890120156]
+#-----------------------------------------账务汇总列表----------------------------------------
+业务类型,收入笔数,收入金额（+元）,支出笔数,支出金额（-元）
+在线支付,1,10.00,0,0.00
+#-----------------------------------------账务汇总列表结束------------------------------------
+`
+
+// ZIP中的文件
+type file struct {
+	name    string
+	content string
+}
+
+func main() {
+	// 汇总文件放在明细文件之前，用于测试按文件名跳过汇总文件
+	write("20190801.zip", []file{
+		{"20881234567890120156_20190801_业务明细(汇总).csv", tradeSummary},
+		{"20881234567890120156_20190801_业务明细.csv", tradeDetail},
+		{"20881234567890120156_20190801_账务明细(汇总).csv", accountSummary},
+		{"20881234567890120156_20190801_账务明细.csv", accountDetail},
+	})
+	// 只有业务明细的账单，用于测试缺少明细文件
+	write("trade_only.zip", []file{
+		{"20881234567890120156_20190801_业务明细.csv", tradeDetail},
+	})
+}
+
+// 按GBK编码写入ZIP文件
+func write(path string, files []file) {
+	out, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer out.Close()
+
+	encoder := simplifiedchinese.GBK.NewEncoder()
+	writer := zip.NewWriter(out)
+	for k := range files {
+		name, err := encoder.String(files[k].name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		w, err := writer.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			NonUTF8:  true,
+			Method:   zip.Deflate,
+			Modified: time.Date(2019, 8, 2, 10, 29, 43, 0, time.UTC),
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		content, err := encoder.String(files[k].content)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if _, err = w.Write([]byte(content)); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
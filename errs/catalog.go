@@ -0,0 +1,158 @@
+package errs
+
+import "regexp"
+
+// 消息目录中的一条翻译，pattern匹配中文错误信息，en中的$1、$2等引用pattern中的分组，
+// 分组内容会先被递归翻译，再将其中的中文连接词替换成英文
+type message struct {
+	pattern *regexp.Regexp
+	en      string
+}
+
+// 创建消息目录条目
+func newMessage(pattern, en string) message {
+	return message{
+		pattern: regexp.MustCompile("^" + pattern + "$"),
+		en:      en,
+	}
+}
+
+// 消息目录，按顺序匹配，较具体的条目必须排在较宽泛的条目之前
+var catalog = []message{
+	// 错误类型
+	newMessage(`参数校验失败`, "validation failed"),
+	newMessage(`签名校验失败`, "signature verification failed"),
+	newMessage(`无法获得支付宝公钥证书`, "Alipay public key certificate not available"),
+	newMessage(`支付宝公钥证书校验失败`, "Alipay public key certificate verification failed"),
+	newMessage(`证书格式无效`, "invalid certificate format"),
+	newMessage(`密钥无效`, "invalid key"),
+	newMessage(`解密失败`, "decryption failed"),
+
+	// 配置
+	newMessage(`无法获得SN为(.*)的支付宝公钥证书`, "Alipay public key certificate with SN $1 not available"),
+	newMessage(`签名不是有效的Base64编码`, "the signature is not valid Base64"),
+	newMessage(`支付宝根证书数据格式无效`, "invalid Alipay root certificate data"),
+	newMessage(`支付宝根证书的SN计算失败`, "failed to compute the SN of the Alipay root certificate"),
+	newMessage(`支付宝公钥证书格式无效`, "invalid Alipay public key certificate"),
+	newMessage(`支付宝公钥证书格式无效：(.+)`, "invalid Alipay public key certificate: $1"),
+	newMessage(`支付宝公钥证书校验失败：(.+)`, "Alipay public key certificate verification failed: $1"),
+	newMessage(`支付宝应用公钥证书格式无效`, "invalid app public key certificate"),
+	newMessage(`支付宝应用私钥无效`, "invalid app private key"),
+	newMessage(`支付宝应用私钥无效：(.+)`, "invalid app private key: $1"),
+	newMessage(`证书格式无效：(.+)`, "invalid certificate format: $1"),
+	newMessage(`仅支持RSA和SM2公钥证书`, "only RSA and SM2 public key certificates are supported"),
+	newMessage(`未加载支付宝根证书，无法校验支付宝公钥证书`, "the Alipay root certificate is not loaded, unable to verify the Alipay public key certificate"),
+	newMessage(`应用签名器不能为nil`, "the app signer must not be nil"),
+	newMessage(`不支持的支付宝公钥类型`, "unsupported Alipay public key type"),
+	newMessage(`仅支持RSA\(SHA1\)、RSA2\(SHA256\)和SM2\(SM3\)三种签名算法`, "only RSA (SHA1), RSA2 (SHA256) and SM2 (SM3) signature algorithms are supported"),
+	newMessage(`签名类型必须是RSA、RSA2\(推荐\)或SM2`, "the sign type must be RSA, RSA2 (recommended) or SM2"),
+	newMessage(`接口内容加密密钥必须是Base64编码的字符串`, "the content encryption key must be a Base64 string"),
+	newMessage(`接口内容加密密钥的长度必须是128、192或256位`, "the content encryption key must be 128, 192 or 256 bits long"),
+	newMessage(`密文必须是Base64编码的字符串`, "the ciphertext must be a Base64 string"),
+	newMessage(`密文长度无效`, "invalid ciphertext length"),
+	newMessage(`解密失败，密钥可能不正确`, "decryption failed, the key may be incorrect"),
+	newMessage(`未设置支付宝配置的AppID参数值`, "AppID is not configured"),
+	newMessage(`未设置支付宝配置的AppSignType参数值`, "AppSignType is not configured"),
+	newMessage(`未设置支付宝配置的应用证书`, "the app public key certificate is not configured"),
+	newMessage(`未设置支付宝配置的根证书`, "the Alipay root certificate is not configured"),
+	newMessage(`未设置支付宝配置的应用私钥或签名器`, "the app private key or signer is not configured"),
+	newMessage(`未设置支付宝配置的接口内容加密密钥`, "the content encryption key is not configured"),
+
+	// 网关
+	newMessage(`支付宝公钥证书SN不能为空`, "the Alipay public key certificate SN must not be empty"),
+	newMessage(`支付宝公钥证书内容解码失败：(.+)`, "failed to decode the Alipay public key certificate: $1"),
+	newMessage(`下载的支付宝公钥证书SN与请求的SN不一致`, "the SN of the downloaded Alipay public key certificate does not match the requested SN"),
+	newMessage(`支付宝网关响应状态码异常：(.+)`, "unexpected Alipay gateway HTTP status: $1"),
+	newMessage(`支付宝网关响应数据格式无效：(.+)`, "invalid Alipay gateway response data: $1"),
+	newMessage(`支付宝网关响应数据中缺少响应参数`, "the Alipay gateway response is missing the response parameter"),
+	newMessage(`支付宝响应参数解密失败：(.+)`, "failed to decrypt the Alipay response: $1"),
+	newMessage(`支付宝响应缺少签名`, "the Alipay response is missing the signature"),
+	newMessage(`支付宝响应(.+)`, "Alipay response: $1"),
+	newMessage(`biz_content参数值加密失败：(.+)`, "failed to encrypt biz_content: $1"),
+	newMessage(`biz_content参数值序列化成JSON时失败：(.+)`, "failed to marshal biz_content to JSON: $1"),
+	newMessage(`无法获取配置中的应用公钥SN`, "unable to get the app public key certificate SN from the config"),
+	newMessage(`无法获取配置中的支付宝根证书SN`, "unable to get the Alipay root certificate SN from the config"),
+	newMessage(`签名参数未构建`, "the sign parameters have not been built"),
+	newMessage(`异步通知的biz_content解密失败：(.+)`, "failed to decrypt biz_content of the notification: $1"),
+	newMessage(`异步通知的biz_content格式无效：(.+)`, "invalid biz_content in the notification: $1"),
+	newMessage(`时间的格式必须是(.+)：(.*)`, "the time must be in the format $1: $2"),
+	newMessage(`时间必须是JSON字符串`, "the time must be a JSON string"),
+	newMessage(`超时时长必须在1m～15d之间`, "the timeout must be between 1m and 15d"),
+	newMessage(`不支持的编码格式：(.+)`, "unsupported charset: $1"),
+	newMessage(`参数值无法转换成(.+)编码：(.+)`, "parameter value cannot be encoded as $1: $2"),
+	newMessage(`参数值无法按(.+)编码解码：(.+)`, "parameter value cannot be decoded as $1: $2"),
+
+	// 配置注册和监视
+	newMessage(`支付宝配置不能为nil`, "the Alipay config must not be nil"),
+	newMessage(`未注册AppID为(.+)的支付宝配置`, "no Alipay config is registered for AppID $1"),
+	newMessage(`文件检查间隔必须大于0`, "the file check interval must be greater than 0"),
+	newMessage(`未指定需要监视的文件`, "no files to watch are specified"),
+
+	// 授权
+	newMessage(`授权回跳参数中缺少(.+)`, "the authorization redirect is missing $1"),
+	newMessage(`未找到应用授权令牌`, "app auth token not found"),
+	newMessage(`刷新令牌已过期，需要商户重新授权`, "the refresh token has expired, the merchant must authorize again"),
+	newMessage(`支付宝未返回应用授权令牌`, "Alipay did not return an app auth token"),
+	newMessage(`支付宝未返回访问令牌`, "Alipay did not return an access token"),
+	newMessage(`令牌的AuthAppID`, "the AuthAppID of the token"),
+	newMessage(`应用授权码`, "the app auth code"),
+	newMessage(`刷新令牌`, "the refresh token"),
+	newMessage(`授权码`, "the auth code"),
+	newMessage(`访问令牌`, "the access token"),
+	newMessage(`授权范围`, "the scope"),
+
+	// 账单
+	newMessage(`账单类型`, "the bill type"),
+	newMessage(`账单时间`, "the bill date"),
+	newMessage(`支付宝未返回账单下载地址`, "Alipay did not return a bill download URL"),
+	newMessage(`账单下载失败：(.+)`, "failed to download the bill: $1"),
+	newMessage(`对账单中不存在业务明细.csv文件`, "the bill does not contain the trade detail file"),
+	newMessage(`对账单中不存在账务明细.csv文件`, "the bill does not contain the account detail file"),
+	newMessage(`对账单中不存在(.+)文件`, "the bill does not contain a $1 file"),
+	newMessage(`对账单中缺少列名行`, "the bill is missing the header row"),
+	newMessage(`对账单中(.+)列的金额格式无效：(.*)`, "invalid amount in column $1 of the bill: $2"),
+
+	// 订单和对账
+	newMessage(`异步通知参数不能为nil`, "the notification params must not be nil"),
+	newMessage(`异步通知中缺少trade_status参数`, "the notification is missing trade_status"),
+	newMessage(`交易查询结果不能为nil`, "the trade query result must not be nil"),
+	newMessage(`交易查询结果中缺少trade_status参数`, "the trade query result is missing trade_status"),
+	newMessage(`交易查询结果中的total_amount格式无效：(.*)`, "invalid total_amount in the trade query result: $1"),
+	newMessage(`缺少商户订单号`, "the out trade no is missing"),
+	newMessage(`无效的交易状态：(.*)`, "invalid trade status: $1"),
+	newMessage(`订单(.+)的金额与支付宝交易金额不一致`, "the amount of order $1 does not match the Alipay trade amount"),
+	newMessage(`未找到订单`, "order not found"),
+	newMessage(`订单状态已被修改`, "the order status has been modified"),
+	newMessage(`订单的OutTradeNo`, "the OutTradeNo of the order"),
+	newMessage(`商户订单号和支付宝交易号不能同时为空`, "the out trade no and the trade no must not both be empty"),
+
+	// 参数校验，带条件的条目必须排在通用条目之前
+	newMessage(`(.+)参数值在(.+)为(.+)时不能小于(.+)`, "$1 must not be less than $4 when $2 is $3"),
+	newMessage(`(.+)为(.+)时(.+)参数必须赋值`, "$3 must be set when $1 is $2"),
+	newMessage(`(.+)参数中商品金额的合计(.+)必须等于(.+)参数值`, "the sum of the goods amounts in $1 ($2) must equal $3"),
+	newMessage(`(.+)中的(.+)参数与已赋值的参数冲突`, "$2 in $1 conflicts with a parameter that is already set"),
+	newMessage(`Params中的(.+)参数与公共请求参数冲突`, "$1 in Params conflicts with a common request parameter"),
+	newMessage(`(.+)与(.+)参数互斥，只能使用其中一个`, "$1 and $2 are mutually exclusive, only one of them can be used"),
+	newMessage(`(.+)参数至少要赋值一个`, "at least one of $1 must be set"),
+	newMessage(`(.+)参数未赋值`, "$1 is required"),
+	newMessage(`(.+)参数值的长度不能大于(\d+)个字符`, "the length of $1 must not exceed $2 characters"),
+	newMessage(`(.+)参数值的?长度不能大于(\d+)`, "the length of $1 must not exceed $2"),
+	newMessage(`(.+)参数值的数量不能大于(\d+)`, "$1 must not contain more than $2 items"),
+	newMessage(`(.+)参数值只能是数字`, "$1 must be numeric"),
+	newMessage(`(.+)参数值只能是(.+)`, "$1 must be $2"),
+	newMessage(`(.+)参数值的范围必须是(.+)`, "$1 must be in the range $2"),
+	newMessage(`(.+)参数值必须大于(.+)`, "$1 must be greater than $2"),
+	newMessage(`(.+)参数值不能小于(.+)`, "$1 must not be less than $2"),
+	newMessage(`(.+?)(?:的参数值|参数值值的)格式不正确`, "$1 has an invalid format"),
+	newMessage(`(.+)参数值必须是以2088开头的16位数字`, "$1 must be a 16-digit number starting with 2088"),
+	newMessage(`(.+)参数值必须是最多两位小数的金额`, "$1 must be an amount with at most two decimal places"),
+	newMessage(`(.+)参数值必须是有效的JSON格式`, "$1 must be valid JSON"),
+	newMessage(`(.+)参数值必须是大于或等于0的整数`, "$1 must be an integer greater than or equal to 0"),
+	newMessage(`(.+)参数值必须是http://或https://开头`, "$1 must start with http:// or https://"),
+	newMessage(`(.+)参数值必须是1m～15d之间以m、h、d为单位的整数时长或1c`, "$1 must be a whole number of minutes (m), hours (h) or days (d) between 1m and 15d, or 1c"),
+	newMessage(`(.+)参数值的格式必须是(.+)`, "$1 must be in the format $2"),
+	newMessage(`(.+?)的?参数值必须是(.+)`, "$1 must be $2"),
+	newMessage(`(.+)的格式必须是(.+)`, "$1 must be in the format $2"),
+	newMessage(`(.+)只能是(.+)`, "$1 must be $2"),
+	newMessage(`(.+)不能为空`, "$1 must not be empty"),
+}
@@ -0,0 +1,77 @@
+package errs
+
+// 网关返回码的描述和处理建议
+type codeText struct {
+	zh           string // 中文描述
+	zhSuggestion string // 中文处理建议
+	en           string // 英文描述
+	enSuggestion string // 英文处理建议
+}
+
+// 网关返回码(code)的描述和处理建议，业务返回码未收录时使用
+var codes = map[string]codeText{
+	"20000": {"服务不可用", "稍后重新发起请求", "service unavailable", "retry the request later"},
+	"20001": {"授权权限不足", "检查访问令牌或应用授权令牌是否有效", "insufficient authorization", "check that the auth token or app auth token is valid"},
+	"40001": {"缺少必选参数", "补充缺少的参数后重新发起请求", "missing required parameter", "add the missing parameter and retry"},
+	"40002": {"非法的参数", "检查参数格式后重新发起请求", "invalid parameter", "check the parameter format and retry"},
+	"40004": {"业务处理失败", "根据业务返回码处理", "business processing failed", "handle it according to the sub code"},
+	"40006": {"权限不足", "在开放平台为应用开通对应的接口权限", "insufficient permissions", "grant the API permission to the app on the Alipay open platform"},
+}
+
+// 业务返回码(sub_code)的描述和处理建议
+var subCodes = map[string]codeText{
+	// 公共错误
+	"isp.unknow-error":                 {"服务暂不可用", "稍后重新发起请求", "service temporarily unavailable", "retry the request later"},
+	"aop.invalid-auth-token":           {"无效的访问令牌", "重新获取用户授权", "invalid auth token", "obtain a new user authorization"},
+	"aop.auth-token-time-out":          {"访问令牌已过期", "使用刷新令牌重新获取访问令牌", "auth token expired", "refresh the auth token with the refresh token"},
+	"aop.invalid-app-auth-token":       {"无效的应用授权令牌", "重新获取应用授权", "invalid app auth token", "obtain a new app authorization"},
+	"aop.app-auth-token-time-out":      {"应用授权令牌已过期", "使用应用刷新令牌重新获取应用授权令牌", "app auth token expired", "refresh the app auth token with the app refresh token"},
+	"isv.missing-app-id":               {"缺少AppID参数", "检查配置的AppID", "missing app_id", "check the configured AppID"},
+	"isv.invalid-app-id":               {"无效的AppID参数", "检查AppID是否正确以及应用是否已上线", "invalid app_id", "check that the AppID is correct and the app is online"},
+	"isv.invalid-signature":            {"验签出错", "检查签名类型、应用私钥以及支付宝中上传的应用公钥是否匹配", "invalid signature", "check that the sign type and app private key match the app public key uploaded to Alipay"},
+	"isv.invalid-timestamp":            {"非法的时间戳参数", "校准服务器时间后重新发起请求", "invalid timestamp", "synchronize the server clock and retry"},
+	"isv.insufficient-isv-permissions": {"第三方应用权限不足", "检查应用是否已签约对应的产品", "insufficient app permissions", "check that the app has signed up for the product"},
+
+	// 交易
+	"ACQ.SYSTEM_ERROR":                      {"系统错误", "使用相同的参数重新发起请求", "Alipay system error", "retry the request with the same parameters"},
+	"ACQ.INVALID_PARAMETER":                 {"参数无效", "检查请求参数，修改后重新发起请求", "invalid parameter", "check and correct the request parameters, then retry"},
+	"ACQ.ACCESS_FORBIDDEN":                  {"无权限使用接口", "联系支付宝签约对应的产品", "access forbidden", "contact Alipay to sign up for the product"},
+	"ACQ.EXIST_FORBIDDEN_WORD":              {"订单信息中包含违禁词", "修改订单信息后重新发起请求", "the order contains forbidden words", "modify the order information and retry"},
+	"ACQ.PARTNER_ERROR":                     {"应用AppID填写错误", "联系支付宝确认AppID的状态", "wrong AppID", "contact Alipay to confirm the status of the AppID"},
+	"ACQ.TOTAL_FEE_EXCEED":                  {"订单总金额超过限额", "修改订单金额后重新发起请求", "the order amount exceeds the limit", "modify the order amount and retry"},
+	"ACQ.CONTEXT_INCONSISTENT":              {"交易信息被篡改", "更换商户订单号后重新发起请求", "the trade information is inconsistent with the existing trade", "retry with a new out_trade_no"},
+	"ACQ.TRADE_HAS_SUCCESS":                 {"交易已被支付", "确认该笔交易是否为当前买家的，如果是则视为支付成功，否则更换商户订单号后重新发起请求", "the trade has already been paid", "if the trade belongs to the current buyer treat it as paid, otherwise retry with a new out_trade_no"},
+	"ACQ.TRADE_HAS_CLOSE":                   {"交易已经关闭", "更换商户订单号后重新发起请求", "the trade has been closed", "retry with a new out_trade_no"},
+	"ACQ.TRADE_NOT_EXIST":                   {"交易不存在", "检查商户订单号或支付宝交易号是否正确，用户尚未付款时稍后再查询", "the trade does not exist", "check out_trade_no or trade_no, and query again later if the buyer has not paid yet"},
+	"ACQ.TRADE_STATUS_ERROR":                {"交易状态不合法", "查询交易状态后再进行操作", "invalid trade status", "query the trade status before retrying the operation"},
+	"ACQ.TRADE_BUYER_NOT_MATCH":             {"交易买家不匹配", "更换商户订单号后重新发起请求", "the trade buyer does not match", "retry with a new out_trade_no"},
+	"ACQ.BUYER_SELLER_EQUAL":                {"买卖家不能相同", "更换买家后重新付款", "the buyer and seller must not be the same", "pay with a different buyer account"},
+	"ACQ.BUYER_ENABLE_STATUS_FORBID":        {"买家状态非法", "由用户联系支付宝确认账户状态", "the buyer account is restricted", "ask the buyer to contact Alipay about the account status"},
+	"ACQ.BUYER_BALANCE_NOT_ENOUGH":          {"买家余额不足", "提示用户充值或更换付款方式后重新付款", "the buyer's balance is insufficient", "ask the buyer to top up or change the payment method"},
+	"ACQ.BUYER_BANKCARD_BALANCE_NOT_ENOUGH": {"用户银行卡余额不足", "提示用户更换付款方式后重新付款", "the buyer's bank card balance is insufficient", "ask the buyer to change the payment method"},
+	"ACQ.ERROR_BALANCE_PAYMENT_DISABLE":     {"余额支付功能关闭", "提示用户打开余额支付或更换付款方式", "balance payment is disabled", "ask the buyer to enable balance payment or change the payment method"},
+	"ACQ.PAYMENT_AUTH_CODE_INVALID":         {"支付授权码无效", "提示用户刷新付款码后重新扫码", "invalid payment auth code", "ask the buyer to refresh the payment code and scan again"},
+	"ACQ.SELLER_BALANCE_NOT_ENOUGH":         {"卖家余额不足", "商户支付宝账户充值后重新发起请求", "the seller's balance is insufficient", "top up the merchant Alipay account and retry"},
+	"ACQ.REFUND_AMT_NOT_EQUAL_TOTAL":        {"退款金额超限", "检查退款金额是否超过交易可退金额", "the refund amount exceeds the limit", "check that the refund amount does not exceed the refundable amount"},
+	"ACQ.REASON_TRADE_BEEN_FREEZEN":         {"请求退款的交易被冻结", "联系支付宝确认交易状态", "the trade has been frozen", "contact Alipay to confirm the trade status"},
+	"ACQ.DISCORDANT_REPEAT_REQUEST":         {"请求信息不一致", "同一请求号重试时必须使用与原请求相同的参数", "the request is inconsistent with a previous request", "retry with the same parameters as the original request or use a new request number"},
+	"ACQ.TRADE_NOT_ALLOW_REFUND":            {"当前交易不允许退款", "检查交易状态和退款期限", "the trade is not allowed to be refunded", "check the trade status and refund period"},
+	"ACQ.NOT_ALLOW_PARTIAL_REFUND":          {"不支持部分退款", "按交易金额全额退款", "partial refund is not allowed", "refund the full trade amount"},
+	"ACQ.AGREEMENT_NOT_EXIST":               {"用户协议不存在", "检查传入的协议号是否正确", "the agreement does not exist", "check the agreement_no"},
+	"ACQ.AGREEMENT_INVALID":                 {"用户协议失效", "引导用户重新签约", "the agreement is invalid", "ask the user to sign the agreement again"},
+	"ACQ.AGREEMENT_STATUS_NOT_NORMAL":       {"用户协议状态不是NORMAL", "查询协议状态，协议暂停或解约时需要用户重新签约", "the agreement status is not NORMAL", "query the agreement status and ask the user to sign again if it is stopped or unsigned"},
+
+	// 转账
+	"PAYEE_NOT_EXIST":          {"收款账号不存在", "检查收款方账号是否正确", "the payee account does not exist", "check the payee account"},
+	"PAYEE_USERINFO_ERROR":     {"收款方姓名或信息不匹配", "检查收款方姓名是否与账号的实名信息一致", "the payee name does not match the account", "check that the payee name matches the account's real-name information"},
+	"PAYER_BALANCE_NOT_ENOUGH": {"付款方余额不足", "商户支付宝账户充值后重新发起转账", "the payer's balance is insufficient", "top up the merchant Alipay account and retry the transfer"},
+}
+
+// 查找返回码的描述和处理建议，优先使用业务返回码
+func lookupCode(code, subCode string) (codeText, bool) {
+	if text, exists := subCodes[subCode]; exists {
+		return text, true
+	}
+	text, exists := codes[code]
+	return text, exists
+}
@@ -0,0 +1,259 @@
+package errs
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// 参数校验规则
+const (
+	RuleRequired  = "required"   // 必须赋值
+	RuleMaxLength = "max_length" // 长度上限
+	RuleEnum      = "enum"       // 只能是指定的值
+	RuleRange     = "range"      // 取值范围
+	RuleFormat    = "format"     // 格式
+	RuleExclusive = "exclusive"  // 与其它参数互斥
+)
+
+// ErrValidation 参数校验失败，所有ValidationError都可以通过errors.Is(err, ErrValidation)判断
+var ErrValidation = errors.New("参数校验失败")
+
+// 签名校验、证书和密钥相关的错误
+var (
+	ErrSignMismatch = errors.New("签名校验失败")      // 签名与数据不匹配
+	ErrCertNotFound = errors.New("无法获得支付宝公钥证书") // 没有签名所用SN对应的支付宝公钥证书
+	ErrCertVerify   = errors.New("支付宝公钥证书校验失败") // 支付宝公钥证书未通过根证书校验，或与请求的SN不一致
+	ErrInvalidCert  = errors.New("证书格式无效")      // 证书数据无法解析
+	ErrInvalidKey   = errors.New("密钥无效")        // 私钥或加密密钥无法解析
+	ErrDecrypt      = errors.New("解密失败")        // 接口内容解密失败
+)
+
+// ValidationError 参数校验错误
+type ValidationError struct {
+	Field   string   // 参数路径，如BizContent.Subject
+	Rule    string   // 违反的校验规则，如RuleRequired
+	Message string   // 错误信息
+	Key     string   // 消息键，如MsgRequired，不为空时按Args和语言的模板渲染Message，为空时按消息目录翻译Message
+	Args    []string // 消息参数，第一个参数是参数路径
+}
+
+// Error 返回错误信息
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Is 使errors.Is(err, ErrValidation)成立
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// ValidationErrors 多个参数校验错误，用于一次性返回所有未通过校验的参数
+type ValidationErrors []*ValidationError
+
+// Error 返回以分号连接的所有错误信息
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	sep := "; "
+	for k := range e {
+		msgs[k] = e[k].Message
+		if !isASCII(e[k].Message) {
+			sep = "；"
+		}
+	}
+	return strings.Join(msgs, sep)
+}
+
+// Is 使errors.Is(err, ErrValidation)成立
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// As 使errors.As可以从中取出第一个*ValidationError
+func (e ValidationErrors) As(target interface{}) bool {
+	if v, ok := target.(**ValidationError); ok && len(e) > 0 {
+		*v = e[0]
+		return true
+	}
+	return false
+}
+
+// Append 添加校验错误，err为nil时忽略，err为ValidationErrors时展开添加，不是校验错误的err按RuleFormat添加
+func (e *ValidationErrors) Append(err error) {
+	if err == nil {
+		return
+	}
+	switch v := err.(type) {
+	case ValidationErrors:
+		*e = append(*e, v...)
+	case *ValidationError:
+		*e = append(*e, v)
+	default:
+		*e = append(*e, &ValidationError{
+			Rule:    RuleFormat,
+			Message: err.Error(),
+		})
+	}
+}
+
+// Err 没有校验错误时返回nil，否则返回e
+func (e ValidationErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// GatewayError 支付宝网关返回的业务错误
+type GatewayError struct {
+	Code    string // 网关返回码
+	Msg     string // 网关返回码描述
+	SubCode string // 业务返回码
+	SubMsg  string // 业务返回码描述
+	Locale  string // 错误信息的语言，为空时使用LocaleZH
+}
+
+// Error 返回错误信息
+func (e *GatewayError) Error() string {
+	if e.Locale == LocaleEN {
+		msg := "Alipay gateway error: " + e.Code + " " + e.Msg
+		if e.SubCode != "" {
+			msg += ", " + e.SubCode + " " + e.Description()
+		}
+		return msg
+	}
+	msg := "支付宝网关返回错误：" + e.Code + " " + e.Msg
+	if e.SubCode != "" {
+		msg += "，" + e.SubCode + " " + e.SubMsg
+	}
+	return msg
+}
+
+// Description 返回按Locale翻译的业务返回码描述，未收录的业务返回码在中文时返回支付宝响应中的描述
+func (e *GatewayError) Description() string {
+	text, exists := subCodes[e.SubCode]
+	if !exists {
+		if e.SubMsg != "" && e.Locale != LocaleEN {
+			return e.SubMsg
+		}
+		if text, exists = codes[e.Code]; !exists {
+			return e.SubMsg
+		}
+	}
+	if e.Locale == LocaleEN {
+		return text.en
+	}
+	return text.zh
+}
+
+// Suggestion 返回按Locale给出的处理建议，未收录的返回码返回空字符串
+func (e *GatewayError) Suggestion() string {
+	text, exists := lookupCode(e.Code, e.SubCode)
+	if !exists {
+		return ""
+	}
+	if e.Locale == LocaleEN {
+		return text.enSuggestion
+	}
+	return text.zhSuggestion
+}
+
+// Is 判断是否与target的返回码一致，target中为空的Code、SubCode不参与比较，
+// 例如errors.Is(err, &GatewayError{SubCode: "ACQ.TRADE_NOT_EXIST"})
+func (e *GatewayError) Is(target error) bool {
+	t, ok := target.(*GatewayError)
+	if !ok {
+		return false
+	}
+	return (t.Code == "" || t.Code == e.Code) && (t.SubCode == "" || t.SubCode == e.SubCode)
+}
+
+// Required 创建参数未赋值的校验错误
+func Required(field string) error {
+	return InvalidKey(field, RuleRequired, MsgRequired)
+}
+
+// MaxLength 创建参数值长度超过上限的校验错误
+func MaxLength(field string, max int) error {
+	return InvalidKey(field, RuleMaxLength, MsgMaxLength, strconv.Itoa(max))
+}
+
+// MaxRunes 创建参数值字符数超过上限的校验错误，用于按字符而不是字节计算长度的参数
+func MaxRunes(field string, max int) error {
+	return InvalidKey(field, RuleMaxLength, MsgMaxRunes, strconv.Itoa(max))
+}
+
+// InvalidKey 创建带有消息键的校验错误，args是参数路径之后的其它消息参数，错误信息按语言的模板渲染
+func InvalidKey(field, rule, key string, args ...string) error {
+	args = append([]string{field}, args...)
+	return &ValidationError{
+		Field:   field,
+		Rule:    rule,
+		Message: render(key, LocaleZH, args),
+		Key:     key,
+		Args:    args,
+	}
+}
+
+// Invalid 创建其它规则的校验错误，错误信息按消息目录翻译，新的校验错误应优先使用InvalidKey
+func Invalid(field, rule, message string) error {
+	return &ValidationError{
+		Field:   field,
+		Rule:    rule,
+		Message: message,
+	}
+}
+
+// WithPrefix 给校验错误的参数路径和错误信息加上前缀，用于嵌套结构体的校验，err为nil时返回nil
+func WithPrefix(prefix string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var v *ValidationError
+	if errors.As(err, &v) {
+		if v.Key != "" {
+			locale := LocaleZH
+			if v.Message != render(v.Key, LocaleZH, v.Args) {
+				locale = LocaleEN
+			}
+			args := append([]string{prefix + v.Args[0]}, v.Args[1:]...)
+			return &ValidationError{
+				Field:   prefix + v.Field,
+				Rule:    v.Rule,
+				Message: render(v.Key, locale, args),
+				Key:     v.Key,
+				Args:    args,
+			}
+		}
+		return &ValidationError{
+			Field:   prefix + v.Field,
+			Rule:    v.Rule,
+			Message: prefix + v.Message,
+		}
+	}
+	return Wrap(err, prefix+err.Error())
+}
+
+// 带有自定义错误信息的包装错误
+type wrapError struct {
+	msg string
+	err error
+}
+
+// Error 返回错误信息
+func (e *wrapError) Error() string {
+	return e.msg
+}
+
+// Unwrap 返回被包装的错误
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
+
+// Wrap 返回以message为错误信息并包装err的错误，可以通过errors.Is/As判断被包装的错误
+func Wrap(err error, message string) error {
+	return &wrapError{
+		msg: message,
+		err: err,
+	}
+}
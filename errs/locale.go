@@ -0,0 +1,107 @@
+package errs
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 错误信息的语言
+const (
+	LocaleZH = "zh-CN" // 简体中文，默认
+	LocaleEN = "en-US" // 英文
+)
+
+// 翻译时替换的中文连接词，只用于替换后不再包含中文的分组，避免改写调用者传入的中文内容
+var conjunctions = strings.NewReplacer("、", ", ", "或者", " or ", "或", " or ", "和", " and ", "与", " and ")
+
+// CheckLocale 检查语言是否受支持，只能是LocaleZH或LocaleEN
+func CheckLocale(value string) error {
+	if value != LocaleZH && value != LocaleEN {
+		return Invalid("Locale", RuleEnum, "Locale参数值只能是"+LocaleZH+"或"+LocaleEN)
+	}
+	return nil
+}
+
+// Localize 将err的错误信息翻译成locale指定的语言，返回的错误仍可以通过errors.Is/As判断原来的错误，
+// locale为空或err为nil时原样返回err，带有消息键的校验错误和GatewayError可以在语言之间反复转换，
+// 其它错误只能从中文翻译成英文，消息目录中没有对应翻译的错误信息保持不变
+func Localize(err error, locale string) error {
+	if err == nil || locale == "" {
+		return err
+	}
+	switch e := err.(type) {
+	case *ValidationError:
+		if e.Key != "" {
+			return &ValidationError{
+				Field:   e.Field,
+				Rule:    e.Rule,
+				Message: render(e.Key, locale, e.Args),
+				Key:     e.Key,
+				Args:    e.Args,
+			}
+		}
+		return &ValidationError{
+			Field:   e.Field,
+			Rule:    e.Rule,
+			Message: Translate(e.Message, e.Field, locale),
+		}
+	case ValidationErrors:
+		localized := make(ValidationErrors, len(e))
+		for k := range e {
+			localized[k] = Localize(e[k], locale).(*ValidationError)
+		}
+		return localized
+	case *GatewayError:
+		localized := *e
+		localized.Locale = locale
+		return &localized
+	case *wrapError:
+		return &wrapError{
+			msg: Translate(e.msg, "", locale),
+			err: e.err,
+		}
+	}
+	if msg := Translate(err.Error(), "", locale); msg != err.Error() {
+		return Wrap(err, msg)
+	}
+	return err
+}
+
+// Translate 按消息目录将中文错误信息msg翻译成locale指定的语言，只用于没有消息键的错误信息，field为校验错误的参数路径，
+// 用于替换错误信息中的中文参数名称，没有对应翻译时返回msg
+func Translate(msg, field, locale string) string {
+	if locale != LocaleEN {
+		return msg
+	}
+	for k := range catalog {
+		groups := catalog[k].pattern.FindStringSubmatch(msg)
+		if groups == nil {
+			continue
+		}
+		result := catalog[k].en
+		// 从后往前替换，避免$1替换掉$10的前缀
+		for i := len(groups) - 1; i > 0; i-- {
+			arg := Translate(groups[i], "", locale)
+			if replaced := conjunctions.Replace(arg); isASCII(replaced) {
+				arg = replaced
+			}
+			if i == 1 && field != "" && !isASCII(arg) {
+				arg = field
+			}
+			result = strings.Replace(result, "$"+strconv.Itoa(i), arg, -1)
+		}
+		return result
+	}
+	return msg
+}
+
+// 判断字符串中是否只有ASCII字符
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package errs
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 校验错误的消息键，带有消息键的校验错误按语言的模板渲染错误信息，不经过消息目录的翻译，
+// 模板中的{0}、{1}等引用参数，参数总是原样填入，第一个参数是参数路径
+const (
+	MsgRequired        = "required"         // {0}参数未赋值
+	MsgMaxLength       = "max_length"       // {0}参数值的长度不能大于{1}
+	MsgMaxRunes        = "max_runes"        // {0}参数值的长度不能大于{1}个字符
+	MsgMaxItems        = "max_items"        // {0}参数值的数量不能大于{1}
+	MsgEnum            = "enum"             // {0}参数值只能是{1}，{1}是以|分隔的可选值
+	MsgNumeric         = "numeric"          // {0}参数值只能是数字
+	MsgUint            = "uint"             // {0}参数值必须是大于或等于0的整数
+	MsgDuration        = "duration"         // {0}参数值必须是1m～15d之间的时长
+	MsgDatetime        = "datetime"         // {0}参数值的格式必须是{1}
+	MsgURL             = "url"              // {0}参数值必须是http://或https://开头
+	MsgJSON            = "json"             // {0}参数值必须是有效的JSON格式
+	MsgAmount          = "amount"           // {0}参数值必须是最多两位小数的金额
+	MsgUserID          = "userid"           // {0}参数值必须是以2088开头的16位数字
+	MsgRange           = "range"            // {0}参数值的范围必须是{1}
+	MsgGreater         = "gt"               // {0}参数值必须大于{1}
+	MsgMin             = "min"              // {0}参数值不能小于{1}
+	MsgRequiredIf      = "required_if"      // {1}为{2}时{0}参数必须赋值，{2}是以|分隔的可选值
+	MsgRequiredWithout = "required_without" // {0}、{1}参数至少要赋值一个，{1}是以|分隔的参数路径
+	MsgExclusive       = "exclusive"        // {0}与{1}参数互斥
+	MsgConfigMismatch  = "config_mismatch"  // {0}参数值必须与配置中的{1}一致
+)
+
+// 消息模板，{n|or}将以|分隔的参数渲染成“A、B或C”形式的列表，{n|list}渲染成“A、B”形式的列表
+type template struct {
+	zh string
+	en string
+}
+
+// 各消息键的模板
+var templates = map[string]template{
+	MsgRequired:        {"{0}参数未赋值", "{0} is required"},
+	MsgMaxLength:       {"{0}参数值的长度不能大于{1}", "the length of {0} must not exceed {1}"},
+	MsgMaxRunes:        {"{0}参数值的长度不能大于{1}个字符", "the length of {0} must not exceed {1} characters"},
+	MsgMaxItems:        {"{0}参数值的数量不能大于{1}", "{0} must not contain more than {1} items"},
+	MsgEnum:            {"{0}参数值只能是{1|or}", "{0} must be {1|or}"},
+	MsgNumeric:         {"{0}参数值只能是数字", "{0} must be numeric"},
+	MsgUint:            {"{0}参数值必须是大于或等于0的整数", "{0} must be an integer greater than or equal to 0"},
+	MsgDuration:        {"{0}参数值必须是1m～15d之间以m、h、d为单位的整数时长或1c", "{0} must be a whole number of minutes (m), hours (h) or days (d) between 1m and 15d, or 1c"},
+	MsgDatetime:        {"{0}参数值的格式必须是{1}", "{0} must be in the format {1}"},
+	MsgURL:             {"{0}参数值必须是http://或https://开头", "{0} must start with http:// or https://"},
+	MsgJSON:            {"{0}参数值必须是有效的JSON格式", "{0} must be valid JSON"},
+	MsgAmount:          {"{0}参数值必须是最多两位小数的金额", "{0} must be an amount with at most two decimal places"},
+	MsgUserID:          {"{0}参数值必须是以2088开头的16位数字", "{0} must be a 16-digit number starting with 2088"},
+	MsgRange:           {"{0}参数值的范围必须是{1}", "{0} must be in the range {1}"},
+	MsgGreater:         {"{0}参数值必须大于{1}", "{0} must be greater than {1}"},
+	MsgMin:             {"{0}参数值不能小于{1}", "{0} must not be less than {1}"},
+	MsgRequiredIf:      {"{1}为{2|or}时{0}参数必须赋值", "{0} must be set when {1} is {2|or}"},
+	MsgRequiredWithout: {"{0}、{1|list}参数至少要赋值一个", "at least one of {0}, {1|list} must be set"},
+	MsgExclusive:       {"{0}与{1}参数互斥，只能使用其中一个", "{0} and {1} are mutually exclusive, only one of them can be used"},
+	MsgConfigMismatch:  {"{0}参数值必须与配置中的{1}一致", "{0} must match the {1} in the config"},
+}
+
+// 列表的连接词
+var listWords = map[string][2]string{
+	LocaleZH: {"、", "或"},
+	LocaleEN: {", ", " or "},
+}
+
+// 按locale的模板渲染消息键，locale不是LocaleEN时使用中文模板，未知的消息键返回以空格连接的键和参数
+func render(key, locale string, args []string) string {
+	t, exists := templates[key]
+	if !exists {
+		return strings.TrimSpace(key + " " + strings.Join(args, " "))
+	}
+	text := t.zh
+	if locale == LocaleEN {
+		text = t.en
+	} else {
+		locale = LocaleZH
+	}
+	// 从后往前替换，避免{1}替换掉{10}的前缀
+	for i := len(args) - 1; i >= 0; i-- {
+		n := strconv.Itoa(i)
+		values := strings.Split(args[i], "|")
+		text = strings.Replace(text, "{"+n+"|or}", joinList(values, locale, true), -1)
+		text = strings.Replace(text, "{"+n+"|list}", joinList(values, locale, false), -1)
+		text = strings.Replace(text, "{"+n+"}", args[i], -1)
+	}
+	return text
+}
+
+// 将多个值连接成列表，or为true时最后两个值以“或”连接
+func joinList(values []string, locale string, or bool) string {
+	words := listWords[locale]
+	if !or || len(values) == 1 {
+		return strings.Join(values, words[0])
+	}
+	return strings.Join(values[:len(values)-1], words[0]) + words[1] + values[len(values)-1]
+}
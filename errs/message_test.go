@@ -0,0 +1,145 @@
+package errs
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 解析message.go中声明的所有消息键
+func parseMsgKeys(t *testing.T) map[string]string {
+	file, err := parser.ParseFile(token.NewFileSet(), "message.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make(map[string]string)
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for k := range spec.Names {
+			if !strings.HasPrefix(spec.Names[k].Name, "Msg") || k >= len(spec.Values) {
+				continue
+			}
+			if lit, ok := spec.Values[k].(*ast.BasicLit); ok {
+				if value, err := strconv.Unquote(lit.Value); err == nil {
+					keys[spec.Names[k].Name] = value
+				}
+			}
+		}
+		return true
+	})
+	if len(keys) == 0 {
+		t.Fatal("没有解析到消息键")
+	}
+	return keys
+}
+
+func TestTemplates(t *testing.T) {
+	for name, key := range parseMsgKeys(t) {
+		tmpl, exists := templates[key]
+		if !exists {
+			t.Errorf("消息键%s没有模板", name)
+			continue
+		}
+		if tmpl.zh == "" || tmpl.en == "" {
+			t.Errorf("消息键%s缺少中文或英文模板", name)
+		}
+		if !isASCII(tmpl.en) {
+			t.Errorf("消息键%s的英文模板包含非ASCII字符：%s", name, tmpl.en)
+		}
+	}
+}
+
+func TestConstructorsEnglish(t *testing.T) {
+	cases := []error{
+		Required("BizContent.Subject"),
+		MaxLength("BizContent.Subject", 256),
+		MaxRunes("BizContent.Body", 128),
+		ErrValidation,
+		ErrSignMismatch,
+		ErrCertNotFound,
+		ErrCertVerify,
+		ErrInvalidCert,
+		ErrInvalidKey,
+		ErrDecrypt,
+	}
+	for _, key := range parseMsgKeys(t) {
+		cases = append(cases, InvalidKey("BizContent.Field", RuleFormat, key, "A|B", "C|D"))
+	}
+	for _, err := range cases {
+		zh := err.Error()
+		en := Localize(err, LocaleEN).Error()
+		if !isASCII(en) {
+			t.Errorf("%q没有英文翻译，得到%q", zh, en)
+		}
+		if back := Localize(err, LocaleZH).Error(); back != zh {
+			t.Errorf("期望中文错误信息%q，得到%q", zh, back)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		err error
+		zh  string
+		en  string
+	}{
+		{
+			InvalidKey("TradeType", RuleEnum, MsgEnum, "A|B|C"),
+			"TradeType参数值只能是A、B或C",
+			"TradeType must be A, B or C",
+		},
+		{
+			InvalidKey("Code", RuleRequired, MsgRequiredIf, "Type", "X"),
+			"Type为X时Code参数必须赋值",
+			"Code must be set when Type is X",
+		},
+		{
+			InvalidKey("A", RuleRequired, MsgRequiredWithout, "B|C"),
+			"A、B、C参数至少要赋值一个",
+			"at least one of A, B, C must be set",
+		},
+		{
+			// 调用者传入的中文参数原样保留，不被当作连接词替换
+			InvalidKey("Channel", RuleEnum, MsgEnum, "余额和花呗|银行卡"),
+			"Channel参数值只能是余额和花呗或银行卡",
+			"Channel must be 余额和花呗 or 银行卡",
+		},
+		{
+			WithPrefix("BizContent.", MaxLength("Subject", 256)),
+			"BizContent.Subject参数值的长度不能大于256",
+			"the length of BizContent.Subject must not exceed 256",
+		},
+		{
+			WithPrefix("BizContent.", Localize(Required("Subject"), LocaleEN)),
+			"BizContent.Subject参数未赋值",
+			"BizContent.Subject is required",
+		},
+	}
+	for _, c := range cases {
+		var v *ValidationError
+		if !errors.As(c.err, &v) {
+			t.Fatalf("期望ValidationError，得到%T", c.err)
+		}
+		if zh := Localize(c.err, LocaleZH).Error(); zh != c.zh {
+			t.Errorf("期望中文错误信息%q，得到%q", c.zh, zh)
+		}
+		if en := Localize(c.err, LocaleEN).Error(); en != c.en {
+			t.Errorf("期望英文错误信息%q，得到%q", c.en, en)
+		}
+	}
+}
+
+func TestTranslateKeepsChinese(t *testing.T) {
+	// 消息目录翻译时，调用者传入的中文内容中的连接词不被改写
+	msg := Translate("TradeType参数值只能是余额和花呗", "TradeType", LocaleEN)
+	if strings.Contains(msg, " and ") {
+		t.Fatalf("中文参数值不应被改写，得到%q", msg)
+	}
+}
@@ -0,0 +1,45 @@
+package account
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 支付宝资金账户资产查询的接口名称
+const queryMethod = "alipay.fund.account.query"
+
+// QueryBizContent 资金账户资产查询请求参数
+type QueryBizContent struct {
+	AlipayUserID string `json:"alipay_user_id" validate:"required,userid"` // 必填，支付宝会员ID，以2088开头的16位数字
+	AccountType  string `json:"account_type,omitempty"`                    // 查询的账号类型，查询余额账户时固定为ACCTRANS_ACCOUNT
+}
+
+// QueryResult 资金账户资产查询结果
+type QueryResult struct {
+	gateway.Response
+	AvailableAmount string `json:"available_amount"` // 账户可用余额，单位为元
+	FreezeAmount    string `json:"freeze_amount"`    // 冻结金额，单位为元
+}
+
+// Query 查询支付宝资金账户资产，必须使用公钥证书模式的配置
+func Query(alipayConfig *config.Config, bizContent *QueryBizContent, options ...gateway.Option) (*QueryResult, error) {
+	if err := gateway.CheckCertMode(alipayConfig); err != nil {
+		return nil, err
+	}
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+	if bizContent.AccountType == "" {
+		bizContent.AccountType = "ACCTRANS_ACCOUNT"
+	}
+
+	var result QueryResult
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     queryMethod,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 资金授权冻结接口名称
+const (
+	appFreezeMethod     = "alipay.fund.auth.order.app.freeze"
+	voucherCreateMethod = "alipay.fund.auth.order.voucher.create"
+)
+
+// FreezeBizContent APP资金授权冻结请求参数
+type FreezeBizContent struct {
+	OutOrderNo         string `json:"out_order_no" validate:"required,max=64"`                             // 必填，商户授权资金订单号，64个字符以内
+	OutRequestNo       string `json:"out_request_no" validate:"required,max=64"`                           // 必填，商户本次资金操作的请求流水号，64个字符以内
+	OrderTitle         string `json:"order_title" validate:"required,maxrunes=100"`                        // 必填，业务订单的简单描述，如商品名称等，100个字符以内
+	Amount             string `json:"amount" validate:"required,amount,range=0.01-100000000"`              // 必填，需要冻结的金额，单位为元，精确到小数点后两位，取值范围[0.01,100000000.00]
+	ProductCode        string `json:"product_code"`                                                        // 必填，销售产品码，APP资金授权为PRE_AUTH_ONLINE
+	PayeeUserID        string `json:"payee_user_id,omitempty"`                                             // 收款方的支付宝唯一用户号，以2088开头的16位纯数字组成
+	PayeeLogonID       string `json:"payee_logon_id,omitempty"`                                            // 收款方支付宝账号(Email或手机号)
+	PayTimeout         string `json:"pay_timeout,omitempty"`                                               // 该笔订单允许的最晚付款时间，逾期将关闭该笔订单，取值范围：1m～15d
+	ExtraParam         string `json:"extra_param,omitempty"`                                               // 业务扩展参数，JSON格式，如{"category":"RENT_PHONE","serviceId":"2019..."}
+	TransCurrency      string `json:"trans_currency,omitempty"`                                            // 标价币种，amount对应的币种单位
+	SettleCurrency     string `json:"settle_currency,omitempty"`                                           // 商户指定的结算币种
+	SceneCode          string `json:"scene_code,omitempty"`                                                // 场景码，预授权刷脸场景等需要传入
+	EnablePayChannels  string `json:"enable_pay_channels,omitempty"`                                       // 商户可用该参数指定用户可使用的支付渠道，JSON格式
+	DepositProductMode string `json:"deposit_product_mode,omitempty" validate:"enum=DEPOSIT_ONLY|POSTPAY"` // 免押受理台模式，DEPOSIT_ONLY：仅免押，POSTPAY：先享后付
+}
+
+// VoucherCreateBizContent 资金授权发码请求参数，生成的码由用户使用支付宝扫码完成冻结
+type VoucherCreateBizContent struct {
+	OutOrderNo        string `json:"out_order_no" validate:"required,max=64"`                // 必填，商户授权资金订单号，64个字符以内
+	OutRequestNo      string `json:"out_request_no" validate:"required,max=64"`              // 必填，商户本次资金操作的请求流水号，64个字符以内
+	OrderTitle        string `json:"order_title" validate:"required,maxrunes=100"`           // 必填，业务订单的简单描述，如商品名称等，100个字符以内
+	Amount            string `json:"amount" validate:"required,amount,range=0.01-100000000"` // 必填，需要冻结的金额，单位为元，精确到小数点后两位，取值范围[0.01,100000000.00]
+	ProductCode       string `json:"product_code"`                                           // 必填，销售产品码，发码冻结为PRE_AUTH
+	PayeeUserID       string `json:"payee_user_id,omitempty"`                                // 收款方的支付宝唯一用户号，以2088开头的16位纯数字组成
+	PayeeLogonID      string `json:"payee_logon_id,omitempty"`                               // 收款方支付宝账号(Email或手机号)
+	PayTimeout        string `json:"pay_timeout,omitempty"`                                  // 该笔订单允许的最晚付款时间，逾期将关闭该笔订单，取值范围：1m～15d
+	ExtraParam        string `json:"extra_param,omitempty"`                                  // 业务扩展参数，JSON格式
+	TransCurrency     string `json:"trans_currency,omitempty"`                               // 标价币种，amount对应的币种单位
+	SettleCurrency    string `json:"settle_currency,omitempty"`                              // 商户指定的结算币种
+	EnablePayChannels string `json:"enable_pay_channels,omitempty"`                          // 商户可用该参数指定用户可使用的支付渠道，JSON格式
+}
+
+// VoucherCreateResult 资金授权发码结果
+type VoucherCreateResult struct {
+	gateway.Response
+	OutOrderNo   string `json:"out_order_no"`   // 商户授权资金订单号
+	OutRequestNo string `json:"out_request_no"` // 商户本次资金操作的请求流水号
+	CodeType     string `json:"code_type"`      // 码类型，目前只支持支付宝二维码(barCode)
+	CodeValue    string `json:"code_value"`     // 码值，用于生成二维码
+	CodeURL      string `json:"code_url"`       // 二维码图片的URL地址
+}
+
+// AppFreeze 构建APP资金授权冻结的请求参数字符串，交给APP中的支付宝SDK调起授权，结果通过异步通知获得
+func AppFreeze(alipayConfig *config.Config, notifyURL string, bizContent *FreezeBizContent, options ...gateway.Option) (string, error) {
+	if bizContent == nil {
+		return "", alipayConfig.Localize(errs.Required("BizContent"))
+	}
+	if bizContent.ProductCode == "" {
+		bizContent.ProductCode = "PRE_AUTH_ONLINE"
+	}
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return "", alipayConfig.Localize(err)
+	}
+	return gateway.BuildQuery(alipayConfig, &gateway.Request{
+		Method:     appFreezeMethod,
+		NotifyURL:  notifyURL,
+		BizContent: bizContent,
+	}, options...)
+}
+
+// VoucherCreate 资金授权发码，生成用于用户扫码冻结的二维码
+func VoucherCreate(alipayConfig *config.Config, notifyURL string, bizContent *VoucherCreateBizContent, options ...gateway.Option) (*VoucherCreateResult, error) {
+	if bizContent == nil {
+		return nil, alipayConfig.Localize(errs.Required("BizContent"))
+	}
+	if bizContent.ProductCode == "" {
+		bizContent.ProductCode = "PRE_AUTH"
+	}
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
+	var result VoucherCreateResult
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     voucherCreateMethod,
+		NotifyURL:  notifyURL,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 资金授权操作查询接口名称
+const queryMethod = "alipay.fund.auth.operation.detail.query"
+
+// 资金操作类型
+const (
+	OperationFreeze   = "FREEZE"   // 冻结
+	OperationUnfreeze = "UNFREEZE" // 解冻
+	OperationPay      = "PAY"      // 支付，即冻结资金转支付
+)
+
+// QueryBizContent 资金授权操作查询请求参数，AuthNo与OutOrderNo至少传入一个，OperationID与OutRequestNo至少传入一个
+type QueryBizContent struct {
+	AuthNo        string `json:"auth_no,omitempty" validate:"required_without=OutOrderNo"`        // 支付宝资金授权订单号
+	OutOrderNo    string `json:"out_order_no,omitempty"`                                          // 商户授权资金订单号
+	OperationID   string `json:"operation_id,omitempty" validate:"required_without=OutRequestNo"` // 支付宝资金操作流水号
+	OutRequestNo  string `json:"out_request_no,omitempty"`                                        // 商户资金操作的请求流水号
+	OperationType string `json:"operation_type,omitempty" validate:"enum=FREEZE|UNFREEZE|PAY"`    // 支付宝资金操作类型，FREEZE、UNFREEZE或PAY
+}
+
+// QueryResult 资金授权操作查询结果
+type QueryResult struct {
+	gateway.Response
+	AuthNo                  string     `json:"auth_no"`                    // 支付宝资金授权订单号
+	OutOrderNo              string     `json:"out_order_no"`               // 商户授权资金订单号
+	OrderStatus             string     `json:"order_status"`               // 资金授权订单状态，INIT：初始，AUTHORIZED：已授权，FINISH：完成，CLOSED：关闭
+	TotalFreezeAmount       string     `json:"total_freeze_amount"`        // 订单累计的冻结金额，单位为元
+	RestAmount              string     `json:"rest_amount"`                // 订单总共剩余的冻结金额，单位为元
+	TotalPayAmount          string     `json:"total_pay_amount"`           // 订单累计用于支付的金额，单位为元
+	OrderTitle              string     `json:"order_title"`                // 业务订单的简单描述
+	PayerLogonID            string     `json:"payer_logon_id"`             // 付款方支付宝账号登录号
+	PayerUserID             string     `json:"payer_user_id"`              // 付款方支付宝账号UID
+	ExtraParam              string     `json:"extra_param"`                // 商户请求创建预授权订单时传入的扩展参数
+	OperationID             string     `json:"operation_id"`               // 支付宝资金操作流水号
+	OutRequestNo            string     `json:"out_request_no"`             // 商户资金操作的请求流水号
+	Amount                  string     `json:"amount"`                     // 该笔资金操作流水的金额，单位为元
+	OperationType           string     `json:"operation_type"`             // 支付宝资金操作类型，FREEZE、UNFREEZE或PAY
+	Status                  string     `json:"status"`                     // 资金操作流水的状态，INIT：初始，SUCCESS：成功，CLOSED：关闭
+	Remark                  string     `json:"remark"`                     // 商户对本次操作的附言描述
+	GmtCreate               model.Time `json:"gmt_create"`                 // 资金授权单据操作流水创建时间
+	GmtTrans                model.Time `json:"gmt_trans"`                  // 支付宝账务处理成功时间
+	PreAuthType             string     `json:"pre_auth_type"`              // 预授权类型，CREDIT_AUTH表示信用预授权
+	TransCurrency           string     `json:"trans_currency"`             // 标价币种
+	TotalFreezeCreditAmount string     `json:"total_freeze_credit_amount"` // 累计冻结信用金额，单位为元
+	TotalFreezeFundAmount   string     `json:"total_freeze_fund_amount"`   // 累计冻结自有资金金额，单位为元
+	TotalPayCreditAmount    string     `json:"total_pay_credit_amount"`    // 累计支付信用金额，单位为元
+	TotalPayFundAmount      string     `json:"total_pay_fund_amount"`      // 累计支付自有资金金额，单位为元
+	RestCreditAmount        string     `json:"rest_credit_amount"`         // 剩余冻结信用金额，单位为元
+	RestFundAmount          string     `json:"rest_fund_amount"`           // 剩余冻结自有资金金额，单位为元
+	CreditAmount            string     `json:"credit_amount"`              // 该笔资金操作流水中的信用金额，单位为元
+	FundAmount              string     `json:"fund_amount"`                // 该笔资金操作流水中的自有资金金额，单位为元
+}
+
+// Query 查询资金授权订单的某一笔资金操作
+func Query(alipayConfig *config.Config, bizContent *QueryBizContent, options ...gateway.Option) (*QueryResult, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
+	var result QueryResult
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     queryMethod,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 资金授权解冻接口名称
+const unfreezeMethod = "alipay.fund.auth.order.unfreeze"
+
+// UnfreezeBizContent 资金授权解冻请求参数
+type UnfreezeBizContent struct {
+	AuthNo       string `json:"auth_no" validate:"required"`                            // 必填，支付宝资金授权订单号
+	OutRequestNo string `json:"out_request_no" validate:"required,max=64"`              // 必填，商户本次资金操作的请求流水号，同一资金授权订单下不能重复
+	Amount       string `json:"amount" validate:"required,amount,range=0.01-100000000"` // 必填，本次操作解冻的金额，单位为元，精确到小数点后两位，取值范围[0.01,100000000.00]
+	Remark       string `json:"remark" validate:"required,maxrunes=100"`                // 必填，商户对本次解冻操作的附言描述，100个字符以内
+	ExtraParam   string `json:"extra_param,omitempty"`                                  // 解冻扩展信息，JSON格式，如信用服务完结时传入{"unfreezeBizInfo":"{\"bizComplete\":\"true\"}"}
+}
+
+// UnfreezeResult 资金授权解冻结果
+type UnfreezeResult struct {
+	gateway.Response
+	AuthNo       string     `json:"auth_no"`        // 支付宝资金授权订单号
+	OutOrderNo   string     `json:"out_order_no"`   // 商户授权资金订单号
+	OperationID  string     `json:"operation_id"`   // 支付宝资金操作流水号
+	OutRequestNo string     `json:"out_request_no"` // 商户本次资金操作的请求流水号
+	Amount       string     `json:"amount"`         // 本次解冻的金额，单位为元
+	Status       string     `json:"status"`         // 资金操作流水的状态，INIT：初始，SUCCESS：成功，CLOSED：关闭
+	GmtTrans     model.Time `json:"gmt_trans"`      // 授权资金解冻成功时间
+	CreditAmount string     `json:"credit_amount"`  // 本次解冻操作中信用解冻金额，单位为元
+	FundAmount   string     `json:"fund_amount"`    // 本次解冻操作中自有资金解冻金额，单位为元
+}
+
+// Unfreeze 解冻资金授权订单中的全部或部分冻结资金
+func Unfreeze(alipayConfig *config.Config, bizContent *UnfreezeBizContent, options ...gateway.Option) (*UnfreezeResult, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
+	var result UnfreezeResult
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     unfreezeMethod,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,60 @@
+package trans
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 转账业务单据查询的接口名称
+const queryMethod = "alipay.fund.trans.common.query"
+
+// QueryBizContent 转账业务单据查询请求参数，OrderID、PayFundOrderID、OutBizNo至少传入一个
+type QueryBizContent struct {
+	ProductCode    string `json:"product_code,omitempty"`                                                 // 业务产品码，单笔无密转账到支付宝账户为TRANS_ACCOUNT_NO_PWD
+	BizScene       string `json:"biz_scene,omitempty"`                                                    // 业务场景，单笔无密转账为DIRECT_TRANSFER
+	OutBizNo       string `json:"out_biz_no,omitempty"`                                                   // 商户订单号
+	OrderID        string `json:"order_id,omitempty" validate:"required_without=PayFundOrderID|OutBizNo"` // 支付宝转账订单号
+	PayFundOrderID string `json:"pay_fund_order_id,omitempty"`                                            // 支付宝支付资金流水号
+}
+
+// QueryResult 转账业务单据查询结果
+type QueryResult struct {
+	gateway.Response
+	OrderID        string     `json:"order_id"`          // 支付宝转账订单号
+	PayFundOrderID string     `json:"pay_fund_order_id"` // 支付宝支付资金流水号
+	OutBizNo       string     `json:"out_biz_no"`        // 商户订单号
+	TransAmount    string     `json:"trans_amount"`      // 付款金额，单位为元
+	Status         string     `json:"status"`            // 转账单据状态，SUCCESS：成功，FAIL：失败，DEALING：处理中，REFUND：退票
+	PayDate        model.Time `json:"pay_date"`          // 支付时间
+	ArrivalTimeEnd model.Time `json:"arrival_time_end"`  // 预计到账时间
+	OrderFee       string     `json:"order_fee"`         // 预计收费金额，单位为元
+	ErrorCode      string     `json:"error_code"`        // 查询到的订单状态为FAIL失败或REFUND退票时，返回错误代码
+	FailReason     string     `json:"fail_reason"`       // 查询到的订单状态为FAIL失败或REFUND退票时，返回具体的原因
+}
+
+// Query 查询转账业务单据，必须使用公钥证书模式的配置
+func Query(alipayConfig *config.Config, bizContent *QueryBizContent, options ...gateway.Option) (*QueryResult, error) {
+	if err := gateway.CheckCertMode(alipayConfig); err != nil {
+		return nil, err
+	}
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+	if bizContent.OutBizNo != "" && bizContent.ProductCode == "" {
+		bizContent.ProductCode = "TRANS_ACCOUNT_NO_PWD"
+	}
+	if bizContent.OutBizNo != "" && bizContent.BizScene == "" {
+		bizContent.BizScene = "DIRECT_TRANSFER"
+	}
+
+	var result QueryResult
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     queryMethod,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,86 @@
+package trans
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 单笔转账的接口名称
+const transferMethod = "alipay.fund.trans.uni.transfer"
+
+// 收款方标识类型
+const (
+	IdentityTypeUserID  = "ALIPAY_USER_ID"  // 支付宝用户的UserID
+	IdentityTypeLogonID = "ALIPAY_LOGON_ID" // 支付宝登录号，支持邮箱和手机号格式，必须同时传入收款方真实姓名
+	IdentityTypeOpenID  = "ALIPAY_OPEN_ID"  // 支付宝用户在应用下的OpenID
+)
+
+// TransferBizContent 单笔转账请求参数
+type TransferBizContent struct {
+	OutBizNo       string       `json:"out_biz_no" validate:"required,max=64"`                       // 必填，商户端的唯一订单号，最大长度64
+	TransAmount    string       `json:"trans_amount" validate:"required,amount,range=0.1-100000000"` // 必填，订单总金额，单位为元，精确到小数点后两位，取值范围[0.1,100000000]
+	ProductCode    string       `json:"product_code" validate:"enum=TRANS_ACCOUNT_NO_PWD"`           // 必填，业务产品码，单笔无密转账到支付宝账户固定为TRANS_ACCOUNT_NO_PWD
+	BizScene       string       `json:"biz_scene" validate:"enum=DIRECT_TRANSFER"`                   // 必填，业务场景，单笔无密转账固定为DIRECT_TRANSFER
+	OrderTitle     string       `json:"order_title,omitempty" validate:"maxrunes=128"`               // 转账业务的标题，用于在支付宝用户的账单里显示，最大长度128
+	PayeeInfo      *Participant `json:"payee_info" validate:"required"`                              // 必填，收款方信息
+	Remark         string       `json:"remark,omitempty" validate:"maxrunes=200"`                    // 业务备注，最大长度200
+	BusinessParams string       `json:"business_params,omitempty"`                                   // 转账业务请求的扩展参数，JSON格式
+}
+
+// Participant 收款方信息
+type Participant struct {
+	Identity     string `json:"identity" validate:"required,max=64"`                                                  // 必填，参与方的标识ID
+	IdentityType string `json:"identity_type" validate:"required,enum=ALIPAY_USER_ID|ALIPAY_LOGON_ID|ALIPAY_OPEN_ID"` // 必填，参与方的标识类型
+	Name         string `json:"name,omitempty" validate:"required_if=IdentityType ALIPAY_LOGON_ID,maxrunes=128"`      // 参与方真实姓名，标识类型为ALIPAY_LOGON_ID时必填
+}
+
+// TransferResult 单笔转账结果
+type TransferResult struct {
+	gateway.Response
+	OutBizNo       string     `json:"out_biz_no"`        // 商户订单号
+	OrderID        string     `json:"order_id"`          // 支付宝转账订单号
+	PayFundOrderID string     `json:"pay_fund_order_id"` // 支付宝支付资金流水号
+	Status         string     `json:"status"`            // 转账单据状态，SUCCESS：成功，FAIL：失败，DEALING：处理中，REFUND：退票
+	TransDate      model.Time `json:"trans_date"`        // 订单支付时间
+}
+
+// Transfer 单笔转账到支付宝账户，必须使用公钥证书模式的配置
+func Transfer(alipayConfig *config.Config, bizContent *TransferBizContent, options ...gateway.Option) (*TransferResult, error) {
+	if err := gateway.CheckCertMode(alipayConfig); err != nil {
+		return nil, err
+	}
+	if bizContent == nil {
+		return nil, alipayConfig.Localize(errs.Required("BizContent"))
+	}
+	if bizContent.ProductCode == "" {
+		bizContent.ProductCode = "TRANS_ACCOUNT_NO_PWD"
+	}
+	if bizContent.BizScene == "" {
+		bizContent.BizScene = "DIRECT_TRANSFER"
+	}
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
+	var result TransferResult
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     transferMethod,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Check 检查收款方标识，标识类型为ALIPAY_USER_ID时标识必须是支付宝UserID
+func (p *Participant) Check() error {
+	if p.IdentityType != IdentityTypeUserID {
+		return nil
+	}
+	return validate.Struct("PayeeInfo", &struct {
+		Identity string `validate:"userid"`
+	}{p.Identity})
+}
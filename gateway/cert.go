@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
+)
+
+// 下载支付宝公钥证书的接口名称
+const certDownloadMethod = "alipay.open.app.alipaycert.download"
+
+// 下载支付宝公钥证书接口的响应参数
+type certDownloadResponse struct {
+	Response
+	AlipayCertContent string `json:"alipay_cert_content"` // 公钥证书Base64后的字符串
+}
+
+// 下载指定SN的支付宝公钥证书，使用支付宝根证书校验通过后缓存到配置中
+func downloadAlipayCert(alipayConfig *config.Config, certSN string) error {
+	if certSN == "" {
+		return errors.New("支付宝公钥证书SN不能为空")
+	}
+
+	body, err := post(alipayConfig, &Request{
+		Method: certDownloadMethod,
+		BizContent: map[string]string{
+			"alipay_cert_sn": certSN,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	content, sign, respCertSN, err := parseResponse(certDownloadMethod, body)
+	if err != nil {
+		return err
+	}
+	plainContent, err := decryptResponse(alipayConfig, content)
+	if err != nil {
+		return err
+	}
+	if err = checkResponse(plainContent); err != nil {
+		return err
+	}
+
+	var resp certDownloadResponse
+	if err = json.Unmarshal(plainContent, &resp); err != nil {
+		return err
+	}
+	certData, err := base64.StdEncoding.DecodeString(resp.AlipayCertContent)
+	if err != nil {
+		return errs.Wrap(errs.ErrInvalidCert, "支付宝公钥证书内容解码失败："+err.Error())
+	}
+
+	// 证书由根证书校验后才会被缓存
+	sn, err := alipayConfig.AddAlipayCert(certData)
+	if err != nil {
+		return err
+	}
+	if sn != certSN {
+		return errs.Wrap(errs.ErrCertVerify, "下载的支付宝公钥证书SN与请求的SN不一致")
+	}
+
+	// 校验本次响应的签名，此时不再触发证书下载
+	if err = alipayConfig.VerifySign(content, sign, respCertSN); err != nil {
+		return errs.Wrap(err, "支付宝响应"+err.Error())
+	}
+	return nil
+}
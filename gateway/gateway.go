@@ -0,0 +1,377 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dxvgef/alipay/charset"
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
+	"github.com/dxvgef/alipay/model"
+)
+
+// API请求地址
+const APIURL = "https://openapi.alipay.com/gateway.do"
+
+// 成功的网关返回码
+const successCode = "10000"
+
+// 请求网关时使用的HTTP客户端
+var HTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// Request 网关请求参数
+type Request struct {
+	Method       string            // 必填，接口名称
+	NotifyURL    string            // 支付宝服务器主动通知商户服务器里指定的页面http/https路径
+	ReturnURL    string            // 页面跳转类接口处理完成后跳转回商户网站的地址，仅用于PageURL和PageForm
+	AppAuthToken string            // 详见应用授权概述
+	BizContent   interface{}       // 请求参数，可以是结构体或map，会被序列化成JSON
+	Params       map[string]string // biz_content之外的其它请求参数，如用户授权接口的grant_type、auth_token等
+	Charset      string            // 请求使用的编码格式，支持utf-8(默认)、gbk和gb2312，参数值按该编码转换后签名和URL编码，Execute只支持utf-8
+	Timestamp    model.Time        // 发送请求的时间，为零值时使用当前时间
+}
+
+// Response 网关响应的公共参数
+type Response struct {
+	Code    string `json:"code"`     // 网关返回码
+	Msg     string `json:"msg"`      // 网关返回码描述
+	SubCode string `json:"sub_code"` // 业务返回码
+	SubMsg  string `json:"sub_msg"`  // 业务返回码描述
+}
+
+// Execute 发送请求到支付宝网关，校验响应签名后将响应参数解析到result，返回的错误信息使用配置的语言。
+// 可以用于调用尚未封装的接口，result可以是调用者定义的结构体(嵌入Response以获得公共响应参数)、
+// *map[string]interface{}或*json.RawMessage，为nil时不解析响应参数，options应用到req的副本
+func Execute(alipayConfig *config.Config, req *Request, result interface{}, options ...Option) error {
+	return alipayConfig.Localize(execute(alipayConfig, applyOptions(req, options), result))
+}
+
+// 发送请求并解析响应
+func execute(alipayConfig *config.Config, req *Request, result interface{}) error {
+	// 支付宝按请求的编码返回响应，非UTF-8的响应无法按JSON解析和验签
+	if name := charset.Normalize(req.Charset); name != "" && name != charset.UTF8 {
+		return errs.Invalid("Charset", errs.RuleEnum, "Charset参数值只能是utf-8")
+	}
+	body, err := post(alipayConfig, req)
+	if err != nil {
+		return err
+	}
+
+	content, sign, certSN, err := parseResponse(req.Method, body)
+	if err != nil {
+		return err
+	}
+
+	// 校验签名，只有网关返回错误时响应中才允许没有签名
+	if sign == "" {
+		if !isErrorResponse(content) {
+			return errs.Wrap(errs.ErrSignMismatch, "支付宝响应缺少签名")
+		}
+	} else if err = verifyResponse(alipayConfig, content, sign, certSN); err != nil {
+		return err
+	}
+
+	// 解密加密的响应参数
+	if content, err = decryptResponse(alipayConfig, content); err != nil {
+		return err
+	}
+
+	if err = checkResponse(content); err != nil {
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(content, result)
+}
+
+// CheckCertMode 检查配置是否可用于公钥证书模式的请求，资金类接口必须使用公钥证书模式
+func CheckCertMode(alipayConfig *config.Config) error {
+	if alipayConfig.GetAppCertPublicKeySN() == "" {
+		return alipayConfig.Localize(errors.New("未设置支付宝配置的应用证书"))
+	}
+	if alipayConfig.GetAlipayRootCertSN() == "" {
+		return alipayConfig.Localize(errors.New("未设置支付宝配置的根证书"))
+	}
+	return nil
+}
+
+// BuildQuery 构建已签名并经过URL编码的请求参数字符串，用于交给客户端SDK调起的接口，如APP支付、APP资金授权冻结
+func BuildQuery(alipayConfig *config.Config, req *Request, options ...Option) (string, error) {
+	values, err := buildValues(alipayConfig, applyOptions(req, options))
+	if err != nil {
+		return "", alipayConfig.Localize(err)
+	}
+	return values.Encode(), nil
+}
+
+// PageURL 构建已签名的页面跳转地址，用于需要将用户重定向到支付宝页面的接口，如手机网站支付、电脑网站支付、页面签约
+func PageURL(alipayConfig *config.Config, req *Request, options ...Option) (string, error) {
+	query, err := BuildQuery(alipayConfig, req, options...)
+	if err != nil {
+		return "", err
+	}
+	return APIURL + "?" + query, nil
+}
+
+// PageForm 构建以POST方式自动提交到支付宝网关的HTML表单，用于页面跳转类接口的请求参数过长、不适合放在URL中的场景
+func PageForm(alipayConfig *config.Config, req *Request, options ...Option) (string, error) {
+	values, err := buildValues(alipayConfig, applyOptions(req, options))
+	if err != nil {
+		return "", alipayConfig.Localize(err)
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var s strings.Builder
+	s.WriteString(`<form id="alipaysubmit" name="alipaysubmit" action="` + APIURL + `?charset=` + html.EscapeString(values.Get("charset")) + `" method="POST">`)
+	for _, k := range keys {
+		s.WriteString(`<input type="hidden" name="` + html.EscapeString(k) + `" value="` + html.EscapeString(values.Get(k)) + `">`)
+	}
+	s.WriteString(`<input type="submit" value="ok" style="display:none;"></form>`)
+	s.WriteString(`<script>document.forms["alipaysubmit"].submit();</script>`)
+	return s.String(), nil
+}
+
+// 发送请求
+func post(alipayConfig *config.Config, req *Request) ([]byte, error) {
+	values, err := buildValues(alipayConfig, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := HTTPClient.PostForm(APIURL, values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("支付宝网关响应状态码异常：" + resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// SignContent 获得请求的待签名字符串，即按名称排序后拼接的UTF-8参数字符串，用于排查签名错误，
+// req.Timestamp为零值时每次调用使用的时间不同
+func SignContent(alipayConfig *config.Config, req *Request, options ...Option) (string, error) {
+	values, err := unsignedValues(alipayConfig, applyOptions(req, options))
+	if err != nil {
+		return "", alipayConfig.Localize(err)
+	}
+	return signContent(values), nil
+}
+
+// 构建已签名的请求参数，非UTF-8编码时按转换后的字节签名，参数值也转换成该编码
+func buildValues(alipayConfig *config.Config, req *Request) (url.Values, error) {
+	values, err := unsignedValues(alipayConfig, req)
+	if err != nil {
+		return nil, err
+	}
+
+	name := values.Get("charset")
+	content, err := charset.Encode(name, signContent(values))
+	if err != nil {
+		return nil, err
+	}
+	sign, err := alipayConfig.Sign([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	for k := range values {
+		for i := range values[k] {
+			if values[k][i], err = charset.Encode(name, values[k][i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	values.Set("sign", sign)
+
+	return values, nil
+}
+
+// 构建未签名的请求参数
+func unsignedValues(alipayConfig *config.Config, req *Request) (url.Values, error) {
+	if req.Method == "" {
+		return nil, errs.Required("Method")
+	}
+	if alipayConfig.GetAppID() == "" {
+		return nil, errors.New("未设置支付宝配置的AppID参数值")
+	}
+	name := charset.Normalize(req.Charset)
+	if name == "" {
+		name = charset.UTF8
+	}
+	if !charset.Supported(name) {
+		return nil, errs.Invalid("Charset", errs.RuleEnum, "Charset参数值只能是utf-8、gbk或gb2312")
+	}
+	timestamp := req.Timestamp
+	if timestamp.IsZero() {
+		timestamp = model.Now()
+	}
+
+	values := make(url.Values)
+	values.Set("app_id", alipayConfig.GetAppID())
+	values.Set("method", req.Method)
+	values.Set("format", "JSON")
+	values.Set("charset", name)
+	values.Set("sign_type", alipayConfig.GetAppSignType())
+	values.Set("timestamp", timestamp.String())
+	values.Set("version", "1.0")
+	if alipayConfig.GetAppCertPublicKeySN() != "" {
+		values.Set("app_cert_sn", alipayConfig.GetAppCertPublicKeySN())
+	}
+	if alipayConfig.GetAlipayRootCertSN() != "" {
+		values.Set("alipay_root_cert_sn", alipayConfig.GetAlipayRootCertSN())
+	}
+	if req.NotifyURL != "" {
+		values.Set("notify_url", req.NotifyURL)
+	}
+	if req.ReturnURL != "" {
+		values.Set("return_url", req.ReturnURL)
+	}
+	if req.AppAuthToken != "" {
+		values.Set("app_auth_token", req.AppAuthToken)
+	}
+	for k := range req.Params {
+		if values.Get(k) != "" {
+			return nil, errs.Invalid("Params."+k, errs.RuleExclusive, "Params中的"+k+"参数与公共请求参数冲突")
+		}
+		values.Set(k, req.Params[k])
+	}
+	if req.BizContent != nil {
+		bizContent, err := json.Marshal(req.BizContent)
+		if err != nil {
+			return nil, errors.New("biz_content参数值序列化成JSON时失败：" + err.Error())
+		}
+		bizContentStr := string(bizContent)
+		// 启用了接口内容加密时，biz_content以密文发送，支付宝解密后按请求的编码读取明文，因此先转换编码再加密
+		if encryptType := alipayConfig.GetEncryptType(); encryptType != "" {
+			plainText, err := charset.Encode(name, bizContentStr)
+			if err != nil {
+				return nil, err
+			}
+			if bizContentStr, err = alipayConfig.Encrypt([]byte(plainText)); err != nil {
+				return nil, errs.Wrap(err, "biz_content参数值加密失败："+err.Error())
+			}
+			values.Set("encrypt_type", encryptType)
+		}
+		values.Set("biz_content", bizContentStr)
+	}
+	return values, nil
+}
+
+// 将参数按名称排序后拼接成待签名字符串，忽略空值和sign参数
+func signContent(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if k == "sign" || values.Get(k) == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var s strings.Builder
+	for k := range keys {
+		if k > 0 {
+			s.WriteString("&")
+		}
+		s.WriteString(keys[k] + "=" + values.Get(keys[k]))
+	}
+	return s.String()
+}
+
+// 解析响应，返回响应参数节点的原始JSON、签名以及签名所用的支付宝公钥证书SN
+func parseResponse(method string, body []byte) (json.RawMessage, string, string, error) {
+	var nodes map[string]json.RawMessage
+	if err := json.Unmarshal(body, &nodes); err != nil {
+		return nil, "", "", errors.New("支付宝网关响应数据格式无效：" + err.Error())
+	}
+
+	content, exists := nodes[strings.Replace(method, ".", "_", -1)+"_response"]
+	if !exists {
+		if content, exists = nodes["error_response"]; !exists {
+			return nil, "", "", errors.New("支付宝网关响应数据中缺少响应参数")
+		}
+	}
+
+	var sign, certSN string
+	if raw, exists := nodes["sign"]; exists {
+		if err := json.Unmarshal(raw, &sign); err != nil {
+			return nil, "", "", err
+		}
+	}
+	if raw, exists := nodes["alipay_cert_sn"]; exists {
+		if err := json.Unmarshal(raw, &certSN); err != nil {
+			return nil, "", "", err
+		}
+	}
+	return content, sign, certSN, nil
+}
+
+// 解密响应参数，启用接口内容加密时响应参数节点是密文字符串，未加密时原样返回
+func decryptResponse(alipayConfig *config.Config, content json.RawMessage) (json.RawMessage, error) {
+	if len(content) == 0 || content[0] != '"' {
+		return content, nil
+	}
+	var cipherText string
+	if err := json.Unmarshal(content, &cipherText); err != nil {
+		return nil, err
+	}
+	plainText, err := alipayConfig.Decrypt(cipherText)
+	if err != nil {
+		return nil, errs.Wrap(err, "支付宝响应参数解密失败："+err.Error())
+	}
+	return plainText, nil
+}
+
+// 判断响应参数是否为网关返回的错误，加密的响应参数不会是错误
+func isErrorResponse(content json.RawMessage) bool {
+	var resp Response
+	if err := json.Unmarshal(content, &resp); err != nil {
+		return false
+	}
+	return resp.Code != "" && resp.Code != successCode
+}
+
+// 检查网关返回码
+func checkResponse(content json.RawMessage) error {
+	var resp Response
+	if err := json.Unmarshal(content, &resp); err != nil {
+		return err
+	}
+	// 部分接口(如alipay.system.oauth.token)成功时不返回code
+	if resp.Code != "" && resp.Code != successCode {
+		return &errs.GatewayError{
+			Code:    resp.Code,
+			Msg:     resp.Msg,
+			SubCode: resp.SubCode,
+			SubMsg:  resp.SubMsg,
+		}
+	}
+	return nil
+}
+
+// 校验响应签名，遇到未缓存的支付宝公钥证书SN时先下载新证书再校验
+func verifyResponse(alipayConfig *config.Config, content []byte, sign, certSN string) error {
+	if alipayConfig.GetAlipayPublicKeyBySN(certSN) == nil {
+		if err := downloadAlipayCert(alipayConfig, certSN); err != nil {
+			return err
+		}
+	}
+	if err := alipayConfig.VerifySign(content, sign, certSN); err != nil {
+		return errs.Wrap(err, "支付宝响应"+err.Error())
+	}
+	return nil
+}
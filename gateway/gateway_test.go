@@ -0,0 +1,133 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dxvgef/alipay/config"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 创建用于测试的配置，使用随机生成的RSA私钥签名
+func testConfig(t *testing.T) *config.Config {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var alipayConfig config.Config
+	if err = alipayConfig.SetAppID("2021000000000000"); err != nil {
+		t.Fatal(err)
+	}
+	if err = alipayConfig.SetAppSignType("RSA2"); err != nil {
+		t.Fatal(err)
+	}
+	if err = alipayConfig.SetAppSigner(key); err != nil {
+		t.Fatal(err)
+	}
+	return &alipayConfig
+}
+
+// 解析页面跳转地址中的参数
+func parsePageURL(t *testing.T, pageURL string) url.Values {
+	if !strings.HasPrefix(pageURL, APIURL+"?") {
+		t.Fatalf("地址应以%s开头：%s", APIURL, pageURL)
+	}
+	values, err := url.ParseQuery(strings.TrimPrefix(pageURL, APIURL+"?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
+
+func TestPageURLEncrypt(t *testing.T) {
+	cases := []struct {
+		charset string
+		decode  func(string) (string, error)
+	}{
+		{"", func(s string) (string, error) { return s, nil }},
+		{"gbk", simplifiedchinese.GBK.NewDecoder().String},
+		{"gb2312", simplifiedchinese.GBK.NewDecoder().String},
+	}
+	for _, c := range cases {
+		t.Run("charset="+c.charset, func(t *testing.T) {
+			alipayConfig := testConfig(t)
+			if err := alipayConfig.SetEncryptKey(base64.StdEncoding.EncodeToString(make([]byte, 16))); err != nil {
+				t.Fatal(err)
+			}
+			pageURL, err := PageURL(alipayConfig, &Request{
+				Method:     "alipay.trade.wap.pay",
+				Charset:    c.charset,
+				BizContent: map[string]string{"subject": "测试商品"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			values := parsePageURL(t, pageURL)
+			if values.Get("encrypt_type") != "AES" {
+				t.Fatalf("期望encrypt_type为AES，得到%q", values.Get("encrypt_type"))
+			}
+
+			// 支付宝解密后按请求的编码读取明文
+			plainText, err := alipayConfig.Decrypt(values.Get("biz_content"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := c.decode(string(plainText))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := `{"subject":"测试商品"}`; content != expected {
+				t.Fatalf("期望明文%s，得到%s", expected, content)
+			}
+		})
+	}
+}
+
+func TestPageURLCharset(t *testing.T) {
+	alipayConfig := testConfig(t)
+	pageURL, err := PageURL(alipayConfig, &Request{
+		Method:     "alipay.trade.wap.pay",
+		Charset:    "GBK",
+		BizContent: map[string]string{"subject": "测试商品"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := parsePageURL(t, pageURL)
+	if values.Get("charset") != "gbk" {
+		t.Fatalf("期望charset为gbk，得到%q", values.Get("charset"))
+	}
+	content, err := simplifiedchinese.GBK.NewDecoder().String(values.Get("biz_content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"subject":"测试商品"}`; content != expected {
+		t.Fatalf("期望biz_content为%s，得到%s", expected, content)
+	}
+
+	if _, err = PageURL(alipayConfig, &Request{Method: "alipay.trade.wap.pay", Charset: "big5"}); err == nil {
+		t.Fatal("不支持的编码格式应返回错误")
+	}
+	if err = Execute(alipayConfig, &Request{Method: "alipay.trade.query", Charset: "gbk"}, nil); err == nil {
+		t.Fatal("Execute使用非UTF-8编码时应返回错误")
+	}
+}
+
+func TestPageURLOptions(t *testing.T) {
+	alipayConfig := testConfig(t)
+	req := &Request{Method: "alipay.user.agreement.page.sign"}
+	pageURL, err := PageURL(alipayConfig, req, WithAppAuthToken("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values := parsePageURL(t, pageURL); values.Get("app_auth_token") != "token" {
+		t.Fatalf("期望app_auth_token为token，得到%q", values.Get("app_auth_token"))
+	}
+	if req.AppAuthToken != "" {
+		t.Fatal("选项不应修改调用者的请求参数")
+	}
+}
@@ -0,0 +1,25 @@
+package gateway
+
+// Option 请求选项，用于给封装好的接口设置函数参数之外的公共请求参数
+type Option func(*Request)
+
+// WithAppAuthToken 设置应用授权令牌，第三方应用代商户调用接口时使用商户授权后获得的app_auth_token
+func WithAppAuthToken(token string) Option {
+	return func(req *Request) {
+		req.AppAuthToken = token
+	}
+}
+
+// 将请求选项应用到请求参数的副本，没有选项时返回req
+func applyOptions(req *Request, options []Option) *Request {
+	if len(options) == 0 || req == nil {
+		return req
+	}
+	applied := *req
+	for k := range options {
+		if options[k] != nil {
+			options[k](&applied)
+		}
+	}
+	return &applied
+}
@@ -2,4 +2,9 @@ module github.com/dxvgef/alipay
 
 go 1.13
 
-require github.com/dxvgef/gommon v0.0.0-20190803095811-5de1f2e05b68
+require (
+	github.com/dxvgef/gommon v0.0.0-20190803095811-5de1f2e05b68
+	github.com/tjfoc/gmsm v1.4.1
+	golang.org/x/crypto v0.0.0-20211117183948-ae814b36b871 // indirect
+	golang.org/x/text v0.13.0
+)
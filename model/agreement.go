@@ -0,0 +1,53 @@
+package model
+
+import "github.com/dxvgef/alipay/errs"
+
+// 周期扣款的周期类型
+const (
+	PeriodTypeDay   = "DAY"   // 按天扣款
+	PeriodTypeMonth = "MONTH" // 按月扣款
+)
+
+// AgreementSignParams 支付并签约时的签约参数
+type AgreementSignParams struct {
+	PersonalProductCode string            `json:"personal_product_code" validate:"required"`         // 必填，个人签约产品码，周期扣款为CYCLE_PAY_AUTH_P
+	SignScene           string            `json:"sign_scene" validate:"required"`                    // 必填，协议签约场景，商户与支付宝签约时确定，如INDUSTRY|DIGITAL_MEDIA
+	ExternalAgreementNo string            `json:"external_agreement_no,omitempty" validate:"max=32"` // 商户签约号，代扣协议中标示用户的唯一签约号
+	ExternalLogonID     string            `json:"external_logon_id,omitempty"`                       // 用户在商户网站的登录账号，用于在签约页面展示
+	AccessParams        *AccessParams     `json:"access_params" validate:"required"`                 // 必填，请求签约时的接入渠道
+	PeriodRuleParams    *PeriodRuleParams `json:"period_rule_params,omitempty"`                      // 周期管控规则参数，周期扣款时必填
+	ProductCode         string            `json:"product_code,omitempty"`                            // 商家和支付宝签约的产品码，周期扣款为CYCLE_PAY_AUTH
+	SignNotifyURL       string            `json:"sign_notify_url,omitempty"`                         // 签约成功后异步通知商户的地址
+	SignValidityPeriod  string            `json:"sign_validity_period,omitempty"`                    // 当前用户签约请求的协议有效周期，取值范围：1d～12m
+	EffectTime          int64             `json:"effect_time,omitempty"`                             // 签约有效时间，单位为秒
+}
+
+// AccessParams 签约的接入渠道
+type AccessParams struct {
+	Channel string `json:"channel" validate:"required,enum=ALIPAYAPP|QRCODE|QRCODEORSMS"` // 必填，目前支持ALIPAYAPP(钱包h5页面签约)、QRCODE(扫码签约)、QRCODEORSMS(扫码签约或者短信签约)
+}
+
+// PeriodRuleParams 周期扣款的管控规则
+type PeriodRuleParams struct {
+	PeriodType    string  `json:"period_type" validate:"required,enum=DAY|MONTH"`       // 必填，周期类型，DAY或MONTH
+	Period        int     `json:"period" validate:"gt=0"`                               // 必填，周期数，与PeriodType组合使用确定扣款周期，如DAY与7表示每7天扣款一次
+	ExecuteTime   string  `json:"execute_time" validate:"required,datetime=2006-01-02"` // 必填，商户发起首次扣款的时间，格式为yyyy-MM-dd
+	SingleAmount  float64 `json:"single_amount" validate:"gt=0"`                        // 必填，单次扣款最大金额，单位为元
+	TotalAmount   float64 `json:"total_amount,omitempty" validate:"min=0"`              // 周期内允许扣款的总金额，单位为元
+	TotalPayments int     `json:"total_payments,omitempty" validate:"min=0"`            // 总扣款次数
+}
+
+// AgreementParams 使用代扣协议支付时的协议参数
+type AgreementParams struct {
+	AgreementNo   string `json:"agreement_no" validate:"required"` // 必填，支付宝系统中用以唯一标识用户签约记录的编号
+	AuthConfirmNo string `json:"auth_confirm_no,omitempty"`        // 鉴权确认码，在需要做支付鉴权校验时，该参数不能为空
+	ApplyToken    string `json:"apply_token,omitempty"`            // 鉴权申请token，在需要做支付鉴权校验时，该参数不能为空
+}
+
+// Check 检查按天扣款时的周期数，周期类型为DAY时周期数不能小于7
+func (obj *PeriodRuleParams) Check() error {
+	if obj.PeriodType == PeriodTypeDay && obj.Period < 7 {
+		return errs.Invalid("PeriodRuleParams.Period", errs.RuleRange, "PeriodRuleParams.Period参数值在PeriodType为DAY时不能小于7")
+	}
+	return nil
+}
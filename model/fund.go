@@ -0,0 +1,8 @@
+package model
+
+// FundBill 交易支付使用的资金渠道
+type FundBill struct {
+	FundChannel string `json:"fund_channel"`          // 支付渠道
+	Amount      string `json:"amount"`                // 使用指定支付渠道支付的金额，单位为元
+	RealAmount  string `json:"real_amount,omitempty"` // 渠道实际付款金额，单位为元
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/dxvgef/alipay/errs"
+)
+
+// GoodsDetail 订单包含的商品明细，用于支付宝的单品营销活动和按商品退款
+type GoodsDetail struct {
+	GoodsID        string  `json:"goods_id" validate:"required,max=64"`         // 必填，商品的编号，64个字符以内
+	AlipayGoodsID  string  `json:"alipay_goods_id,omitempty"`                   // 支付宝定义的统一商品编号
+	GoodsName      string  `json:"goods_name" validate:"required,maxrunes=256"` // 必填，商品名称，256个字符以内
+	Quantity       int     `json:"quantity" validate:"gt=0"`                    // 必填，商品数量
+	Price          float64 `json:"price" validate:"min=0.01"`                   // 必填，商品单价，单位为元
+	GoodsCategory  string  `json:"goods_category,omitempty"`                    // 商品类目
+	CategoriesTree string  `json:"categories_tree,omitempty"`                   // 商品类目树，从商品类目根节点到叶子节点的类目ID组成，最多10层，以|分隔，如124868003|126232002
+	Body           string  `json:"body,omitempty" validate:"maxrunes=1000"`     // 商品描述信息
+	ShowURL        string  `json:"show_url,omitempty" validate:"max=400"`       // 商品的展示地址
+}
+
+// Amount 获得商品的金额，即单价乘以数量，单位为元
+func (g *GoodsDetail) Amount() float64 {
+	return float64(toCents(g.Price)*int64(g.Quantity)) / 100
+}
+
+// GoodsAmount 获得商品明细列表的合计金额，单位为元
+func GoodsAmount(details []GoodsDetail) float64 {
+	var cents int64
+	for k := range details {
+		cents += toCents(details[k].Price) * int64(details[k].Quantity)
+	}
+	return float64(cents) / 100
+}
+
+// CheckGoodsAmount 检查商品明细列表的合计金额是否等于amount，用于需要商品明细与订单金额或退款金额一致的场景，
+// field为错误信息中使用的参数名，amountField为amount对应的参数名，列表为空时不检查
+func CheckGoodsAmount(field string, details []GoodsDetail, amountField string, amount float64) error {
+	if len(details) == 0 {
+		return nil
+	}
+	if sum := GoodsAmount(details); toCents(sum) != toCents(amount) {
+		return errs.Invalid(field, errs.RuleRange, field+"参数中商品金额的合计"+strconv.FormatFloat(sum, 'f', 2, 64)+"必须等于"+amountField+"参数值")
+	}
+	return nil
+}
+
+// 将以元为单位的金额转换成分，避免浮点数累加的误差
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
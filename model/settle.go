@@ -0,0 +1,48 @@
+package model
+
+// 分账收入方账户类型
+const (
+	TransInTypeUserID        = "userId"        // 支付宝用户的UserID
+	TransInTypeLoginName     = "loginName"     // 支付宝登录号
+	TransInTypeOpenID        = "openId"        // 支付宝用户在应用下的OpenID
+	TransInTypeCardAliasNo   = "cardAliasNo"   // 结算收款方的银行卡编号，仅用于SettleDetailInfo
+	TransInTypeDefaultSettle = "defaultSettle" // 结算到商户进件时设置的默认结算账号，仅用于SettleDetailInfo
+)
+
+// SettleInfo 描述结算信息，用于间连商户的交易结算
+type SettleInfo struct {
+	SettleDetailInfos []SettleDetailInfo `json:"settle_detail_infos" validate:"required"` // 必填，结算详细信息，目前只支持一条
+	SettlePeriodTime  string             `json:"settle_period_time,omitempty"`            // 该笔订单的超期自动确认结算时间，到达期限后，将自动确认结算，取值范围：1d～365d
+}
+
+// SettleDetailInfo 结算详细信息
+type SettleDetailInfo struct {
+	TransInType      string  `json:"trans_in_type" validate:"required,enum=cardAliasNo|userId|loginName|defaultSettle"`  // 必填，结算收款方的账户类型，cardAliasNo、userId、loginName或defaultSettle
+	TransIn          string  `json:"trans_in,omitempty" validate:"required_if=TransInType cardAliasNo|userId|loginName"` // 结算收款方，TransInType为defaultSettle时可以不填
+	SummaryDimension string  `json:"summary_dimension,omitempty"`                                                        // 结算汇总维度，按照这个维度汇总成批次结算，由商户指定
+	SettleEntityID   string  `json:"settle_entity_id,omitempty"`                                                         // 结算主体标识，当结算主体类型为SecondMerchant时，为二级商户的SecondMerchantID
+	SettleEntityType string  `json:"settle_entity_type,omitempty"`                                                       // 结算主体类型，二级商户为SecondMerchant，商户或者直连商户门店为Store
+	Amount           float64 `json:"amount" validate:"gt=0"`                                                             // 必填，结算的金额，单位为元
+}
+
+// RoyaltyInfo 描述分账信息，用于支付时直接分账
+type RoyaltyInfo struct {
+	RoyaltyType        string          `json:"royalty_type,omitempty"`         // 分账类型，目前只支持ROYALTY，普通分账
+	RoyaltyDetailInfos []RoyaltyDetail `json:"royalty_detail_infos,omitempty"` // 分账明细的信息，可以描述多条分账指令，JSON数组
+}
+
+// RoyaltyDetail 分账明细
+type RoyaltyDetail struct {
+	RoyaltyType      string  `json:"royalty_type,omitempty" validate:"enum=transfer|replenish"`           // 分账类型，transfer：分账，replenish：营销补差，默认为transfer
+	SerialNo         int     `json:"serial_no,omitempty"`                                                 // 分账序列号，表示分账执行的顺序，必须为正整数
+	TransOut         string  `json:"trans_out,omitempty"`                                                 // 分账支出方账户，为空时默认为交易的卖家
+	TransOutType     string  `json:"trans_out_type,omitempty" validate:"enum=userId|loginName|openId"`    // 分账支出方账户类型，userId、loginName或openId
+	TransInType      string  `json:"trans_in_type,omitempty" validate:"enum=userId|loginName|openId"`     // 分账收入方账户类型，userId、loginName或openId
+	TransIn          string  `json:"trans_in" validate:"required"`                                        // 必填，分账收入方账户
+	TransInName      string  `json:"trans_in_name,omitempty"`                                             // 分账收入方的真实姓名，收入方账户类型为loginName时可用于校验
+	Amount           float64 `json:"amount,omitempty" validate:"min=0,required_without=AmountPercentage"` // 分账的金额，单位为元
+	AmountPercentage int     `json:"amount_percentage,omitempty" validate:"range=0-100"`                  // 分账信息中分账百分比，取值范围为大于0，少于或等于100的整数
+	Desc             string  `json:"desc,omitempty"`                                                      // 分账描述
+	BatchNo          string  `json:"batch_no,omitempty"`                                                  // 分账批次号
+	OutRelationID    string  `json:"out_relation_id,omitempty"`                                           // 商户分账的外部关联号，用于关联到每一笔分账信息
+}
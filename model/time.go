@@ -0,0 +1,144 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// 支付宝接口使用的时间格式
+const (
+	TimeLayout   = "2006-01-02 15:04:05" // yyyy-MM-dd HH:mm:ss
+	MinuteLayout = "2006-01-02 15:04"    // yyyy-MM-dd HH:mm，用于time_expire等精确到分钟的参数
+)
+
+// Shanghai 支付宝接口使用的时区，中国没有夏令时，因此使用固定的东八区，不依赖服务器的时区数据库
+var Shanghai = time.FixedZone("Asia/Shanghai", 8*60*60)
+
+// Time 支付宝接口中精确到秒的时间，无论服务器在哪个时区，都按Asia/Shanghai时区的yyyy-MM-dd HH:mm:ss格式序列化和解析，
+// 零值序列化为空字符串
+type Time struct {
+	time.Time
+}
+
+// MinuteTime 支付宝接口中精确到分钟的时间，按Asia/Shanghai时区的yyyy-MM-dd HH:mm格式序列化和解析，
+// 零值序列化为空字符串
+type MinuteTime struct {
+	time.Time
+}
+
+// NewTime 将t转换成支付宝时间
+func NewTime(t time.Time) Time {
+	return Time{t.In(Shanghai)}
+}
+
+// Now 获得当前的支付宝时间
+func Now() Time {
+	return NewTime(time.Now())
+}
+
+// ParseTime 按Asia/Shanghai时区解析yyyy-MM-dd HH:mm:ss格式的时间，value为空时返回零值
+func ParseTime(value string) (Time, error) {
+	t, err := parse(value, TimeLayout)
+	return Time{t}, err
+}
+
+// String 获得yyyy-MM-dd HH:mm:ss格式的时间字符串，零值时返回空字符串
+func (t Time) String() string {
+	return format(t.Time, TimeLayout)
+}
+
+// MarshalJSON 序列化成yyyy-MM-dd HH:mm:ss格式的JSON字符串
+func (t Time) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(t.String())), nil
+}
+
+// UnmarshalJSON 从yyyy-MM-dd HH:mm:ss格式的JSON字符串解析时间
+func (t *Time) UnmarshalJSON(data []byte) (err error) {
+	t.Time, err = unmarshal(data, TimeLayout)
+	return
+}
+
+// NewMinuteTime 将t转换成精确到分钟的支付宝时间，秒及以下的部分被舍去
+func NewMinuteTime(t time.Time) MinuteTime {
+	return MinuteTime{t.In(Shanghai).Truncate(time.Minute)}
+}
+
+// ParseMinuteTime 按Asia/Shanghai时区解析yyyy-MM-dd HH:mm格式的时间，value为空时返回零值
+func ParseMinuteTime(value string) (MinuteTime, error) {
+	t, err := parse(value, MinuteLayout)
+	return MinuteTime{t}, err
+}
+
+// String 获得yyyy-MM-dd HH:mm格式的时间字符串，零值时返回空字符串
+func (t MinuteTime) String() string {
+	return format(t.Time, MinuteLayout)
+}
+
+// MarshalJSON 序列化成yyyy-MM-dd HH:mm格式的JSON字符串
+func (t MinuteTime) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(t.String())), nil
+}
+
+// UnmarshalJSON 从yyyy-MM-dd HH:mm格式的JSON字符串解析时间
+func (t *MinuteTime) UnmarshalJSON(data []byte) (err error) {
+	t.Time, err = unmarshal(data, MinuteLayout)
+	return
+}
+
+// TimeoutExpress 将时长转换成timeout_express参数值，按能整除的最大单位(d、h、m)表示，不足一分钟的部分被舍去，
+// 时长必须在1m～15d之间
+func TimeoutExpress(d time.Duration) (string, error) {
+	const day = 24 * time.Hour
+	if d < time.Minute || d > 15*day {
+		return "", errors.New("超时时长必须在1m～15d之间")
+	}
+	d = d.Truncate(time.Minute)
+	switch {
+	case d%day == 0:
+		return strconv.FormatInt(int64(d/day), 10) + "d", nil
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h", nil
+	}
+	return strconv.FormatInt(int64(d/time.Minute), 10) + "m", nil
+}
+
+// 按layout格式化Asia/Shanghai时区的时间
+func format(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(Shanghai).Format(layout)
+}
+
+// 按layout解析Asia/Shanghai时区的时间
+func parse(value, layout string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation(layout, value, Shanghai)
+	if err != nil {
+		return time.Time{}, errors.New("时间的格式必须是" + layoutText(layout) + "：" + value)
+	}
+	return t, nil
+}
+
+// 从JSON字符串解析时间，null和空字符串解析为零值
+func unmarshal(data []byte, layout string) (time.Time, error) {
+	if string(data) == "null" {
+		return time.Time{}, nil
+	}
+	value, err := strconv.Unquote(string(data))
+	if err != nil {
+		return time.Time{}, errors.New("时间必须是JSON字符串")
+	}
+	return parse(value, layout)
+}
+
+// 获得支付宝文档中的时间格式
+func layoutText(layout string) string {
+	if layout == MinuteLayout {
+		return "yyyy-MM-dd HH:mm"
+	}
+	return "yyyy-MM-dd HH:mm:ss"
+}
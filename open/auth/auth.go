@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
+)
+
+// 第三方应用授权页面地址
+const AuthURL = "https://openauth.alipay.com/oauth2/appToAppAuth.htm"
+
+// Redirect 商户授权后支付宝回跳到redirect_uri时携带的参数
+type Redirect struct {
+	AppID       string // 第三方应用的AppID
+	AppAuthCode string // 应用授权码，用于换取应用授权令牌，有效期为24小时且只能使用一次
+	State       string // 构建授权链接时传入的自定义参数，原样返回
+}
+
+// BuildURL 构建商户授权链接，商户访问该链接完成授权后，支付宝会带上app_auth_code回跳到redirectURI，
+// state为可选的自定义参数，建议传入随机值用于防止CSRF攻击
+func BuildURL(appID, redirectURI, state string) (string, error) {
+	if appID == "" {
+		return "", errs.Invalid("appID", errs.RuleRequired, "AppID不能为空")
+	}
+	if redirectURI == "" {
+		return "", errs.Invalid("redirectURI", errs.RuleRequired, "redirectURI不能为空")
+	}
+	values := make(url.Values)
+	values.Set("app_id", appID)
+	values.Set("redirect_uri", redirectURI)
+	if state != "" {
+		values.Set("state", state)
+	}
+	return AuthURL + "?" + values.Encode(), nil
+}
+
+// ParseRedirect 解析授权回跳请求中的参数，错误信息使用alipayConfig设置的语言
+func ParseRedirect(alipayConfig *config.Config, req *http.Request) (*Redirect, error) {
+	query := req.URL.Query()
+	redirect := &Redirect{
+		AppID:       query.Get("app_id"),
+		AppAuthCode: query.Get("app_auth_code"),
+		State:       query.Get("state"),
+	}
+	if redirect.AppAuthCode == "" {
+		return nil, alipayConfig.Localize(errs.Invalid("app_auth_code", errs.RuleRequired, "授权回跳参数中缺少app_auth_code"))
+	}
+	return redirect, nil
+}
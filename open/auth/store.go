@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dxvgef/alipay/config"
+)
+
+// ErrTokenNotFound 令牌存储中不存在指定商户的令牌
+var ErrTokenNotFound = errors.New("未找到应用授权令牌")
+
+// TokenStore 应用授权令牌存储，可使用数据库、Redis等实现持久化，实现必须可并发使用
+type TokenStore interface {
+	// Get 获得授权商户AppID对应的令牌，不存在时返回ErrTokenNotFound
+	Get(authAppID string) (*Token, error)
+	// Save 保存令牌，以令牌的AuthAppID作为键
+	Save(token *Token) error
+	// Delete 删除授权商户AppID对应的令牌
+	Delete(authAppID string) error
+}
+
+// MemoryTokenStore 基于内存的令牌存储
+type MemoryTokenStore struct {
+	mutex  sync.RWMutex
+	tokens map[string]Token
+}
+
+// NewMemoryTokenStore 创建基于内存的令牌存储
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: make(map[string]Token),
+	}
+}
+
+// Get 获得令牌
+func (s *MemoryTokenStore) Get(authAppID string) (*Token, error) {
+	s.mutex.RLock()
+	token, exists := s.tokens[authAppID]
+	s.mutex.RUnlock()
+	if !exists {
+		return nil, ErrTokenNotFound
+	}
+	return &token, nil
+}
+
+// Save 保存令牌
+func (s *MemoryTokenStore) Save(token *Token) error {
+	if token == nil || token.AuthAppID == "" {
+		return errors.New("令牌的AuthAppID不能为空")
+	}
+	s.mutex.Lock()
+	s.tokens[token.AuthAppID] = *token
+	s.mutex.Unlock()
+	return nil
+}
+
+// Delete 删除令牌
+func (s *MemoryTokenStore) Delete(authAppID string) error {
+	s.mutex.Lock()
+	delete(s.tokens, authAppID)
+	s.mutex.Unlock()
+	return nil
+}
+
+// Authorize 使用应用授权码换取令牌并保存到令牌存储
+func Authorize(alipayConfig *config.Config, store TokenStore, appAuthCode string) (*Token, error) {
+	token, err := ExchangeToken(alipayConfig, appAuthCode)
+	if err != nil {
+		return nil, err
+	}
+	if err = store.Save(token); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+	return token, nil
+}
+
+// ValidToken 从令牌存储中获得授权商户的令牌，令牌在leeway时间内将过期时自动刷新并保存新令牌
+func ValidToken(alipayConfig *config.Config, store TokenStore, authAppID string, leeway time.Duration) (*Token, error) {
+	token, err := store.Get(authAppID)
+	if err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+	if !token.Expired(leeway) {
+		return token, nil
+	}
+
+	if token.ReExpiresIn > 0 && !time.Now().Before(token.RefreshExpiresAt()) {
+		return nil, alipayConfig.Localize(errors.New("刷新令牌已过期，需要商户重新授权"))
+	}
+	newToken, err := RefreshToken(alipayConfig, token.AppRefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if newToken.AuthAppID == "" {
+		newToken.AuthAppID = token.AuthAppID
+	}
+	if err = store.Save(newToken); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+	return newToken, nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
+	"github.com/dxvgef/alipay/gateway"
+)
+
+// 换取和刷新应用授权令牌的接口名称
+const tokenMethod = "alipay.open.auth.token.app"
+
+// Token 应用授权令牌
+type Token struct {
+	UserID          string    `json:"user_id"`           // 授权商户的UserID
+	AuthAppID       string    `json:"auth_app_id"`       // 授权商户的AppID
+	AppAuthToken    string    `json:"app_auth_token"`    // 应用授权令牌
+	AppRefreshToken string    `json:"app_refresh_token"` // 刷新令牌
+	ExpiresIn       int64     `json:"expires_in"`        // 应用授权令牌的有效时间，单位为秒
+	ReExpiresIn     int64     `json:"re_expires_in"`     // 刷新令牌的有效时间，单位为秒
+	CreatedAt       time.Time `json:"created_at"`        // 令牌的获取时间
+}
+
+// ExpiresAt 获得应用授权令牌的过期时间
+func (t *Token) ExpiresAt() time.Time {
+	return t.CreatedAt.Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
+// RefreshExpiresAt 获得刷新令牌的过期时间
+func (t *Token) RefreshExpiresAt() time.Time {
+	return t.CreatedAt.Add(time.Duration(t.ReExpiresIn) * time.Second)
+}
+
+// Expired 判断应用授权令牌在leeway时间之后是否已过期
+func (t *Token) Expired(leeway time.Duration) bool {
+	return !time.Now().Add(leeway).Before(t.ExpiresAt())
+}
+
+// 接口响应参数
+type tokenResponse struct {
+	gateway.Response
+	Token
+	Tokens []Token `json:"tokens"` // 批量授权时返回的令牌列表
+}
+
+// ExchangeToken 使用应用授权码换取应用授权令牌
+func ExchangeToken(alipayConfig *config.Config, appAuthCode string) (*Token, error) {
+	if appAuthCode == "" {
+		return nil, alipayConfig.Localize(errs.Invalid("appAuthCode", errs.RuleRequired, "应用授权码不能为空"))
+	}
+	return requestToken(alipayConfig, map[string]string{
+		"grant_type": "authorization_code",
+		"code":       appAuthCode,
+	})
+}
+
+// RefreshToken 使用刷新令牌换取新的应用授权令牌
+func RefreshToken(alipayConfig *config.Config, appRefreshToken string) (*Token, error) {
+	if appRefreshToken == "" {
+		return nil, alipayConfig.Localize(errs.Invalid("appRefreshToken", errs.RuleRequired, "刷新令牌不能为空"))
+	}
+	return requestToken(alipayConfig, map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": appRefreshToken,
+	})
+}
+
+// 请求令牌接口
+func requestToken(alipayConfig *config.Config, bizContent map[string]string) (*Token, error) {
+	var resp tokenResponse
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     tokenMethod,
+		BizContent: bizContent,
+	}, &resp); err != nil {
+		return nil, err
+	}
+
+	token := resp.Token
+	if token.AppAuthToken == "" && len(resp.Tokens) > 0 {
+		token = resp.Tokens[0]
+	}
+	if token.AppAuthToken == "" {
+		return nil, alipayConfig.Localize(errors.New("支付宝未返回应用授权令牌"))
+	}
+	token.CreatedAt = time.Now()
+	return &token, nil
+}
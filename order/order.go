@@ -0,0 +1,294 @@
+package order
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/dxvgef/alipay/errs"
+	"github.com/dxvgef/alipay/trade/query"
+	"github.com/dxvgef/alipay/trade/wap/notify"
+)
+
+// 交易状态
+const (
+	StatusWaitBuyerPay  = "WAIT_BUYER_PAY" // 交易创建，等待买家付款
+	StatusTradeSuccess  = "TRADE_SUCCESS"  // 交易支付成功，可以退款
+	StatusTradeFinished = "TRADE_FINISHED" // 交易结束，不可退款
+	StatusTradeClosed   = "TRADE_CLOSED"   // 未付款交易超时关闭，或支付完成后全额退款
+)
+
+// 状态更新的来源
+const (
+	SourceNotify = "notify" // 异步通知
+	SourceQuery  = "query"  // 交易查询
+)
+
+// Order 商户订单
+type Order struct {
+	OutTradeNo   string  // 商户订单号
+	TradeNo      string  // 支付宝交易号，付款前为空
+	Status       string  // 交易状态
+	TotalAmount  float64 // 订单金额，单位为元
+	RefundAmount float64 // 累计退款金额，单位为元
+}
+
+// Transition 一次状态转换，部分退款时From和To都是StatusTradeSuccess
+type Transition struct {
+	OutTradeNo   string  // 商户订单号
+	TradeNo      string  // 支付宝交易号
+	From         string  // 转换前的状态
+	To           string  // 转换后的状态
+	TotalAmount  float64 // 订单金额，单位为元
+	RefundAmount float64 // 转换后的累计退款金额，单位为元
+	RefundDelta  float64 // 本次转换新增的退款金额，单位为元
+	Source       string  // 状态更新的来源，SourceNotify或SourceQuery
+}
+
+// Paid 是否是付款成功的转换，乱序到达的退款通知也意味着交易已付款
+func (t *Transition) Paid() bool {
+	return t.From == StatusWaitBuyerPay && (t.To == StatusTradeSuccess || t.To == StatusTradeFinished || t.RefundDelta > 0)
+}
+
+// Refunded 是否是退款的转换，包括部分退款和全额退款
+func (t *Transition) Refunded() bool {
+	return t.RefundDelta > 0
+}
+
+// Key 获得转换的幂等键，由商户订单号、转换前后的状态和累计退款金额组成，
+// 同一转换因钩子失败而重新应用时键不变，不同的转换键不同
+func (t *Transition) Key() string {
+	return t.OutTradeNo + ":" + t.From + ">" + t.To + ":" + strconv.FormatInt(toCents(t.RefundAmount), 10)
+}
+
+// Closed 是否是交易关闭的转换，包括未付款超时关闭和全额退款
+func (t *Transition) Closed() bool {
+	return t.To == StatusTradeClosed
+}
+
+// Hook 状态转换的钩子函数，用于发货、通知用户等履约操作，同一转换可能被调用多次，必须是幂等的
+type Hook func(t *Transition) error
+
+// Machine 订单状态机，只接受合法的状态转换，乱序或重复的通知会被忽略
+type Machine struct {
+	store  Store
+	hooks  []Hook
+	mutex  sync.Mutex
+	locks  map[string]*orderLock
+	locale string // 错误信息的语言
+}
+
+// 单个订单的锁
+type orderLock struct {
+	sync.Mutex
+	refs int
+}
+
+// 合法的状态转换，部分退款是StatusTradeSuccess到自身的转换
+// 付款通知晚于退款通知到达时，WAIT_BUYER_PAY可以直接转换为带有退款的StatusTradeSuccess或StatusTradeClosed
+var transitions = map[string][]string{
+	StatusWaitBuyerPay: {StatusTradeSuccess, StatusTradeFinished, StatusTradeClosed},
+	StatusTradeSuccess: {StatusTradeSuccess, StatusTradeFinished, StatusTradeClosed},
+}
+
+// New 创建订单状态机
+func New(store Store) *Machine {
+	return &Machine{
+		store: store,
+		locks: make(map[string]*orderLock),
+	}
+}
+
+// OnTransition 注册状态转换的钩子函数，在Store.Save更新订单后按注册顺序调用。
+// 钩子按至少一次(at-least-once)的语义调用：钩子返回错误时停止调用后续钩子，Store撤销本次转换并返回该错误，
+// 重发的通知会再次应用该转换并重新调用所有钩子，已成功的钩子也会被再次调用，
+// 因此钩子必须是幂等的，可以用Transition.Key()作为幂等键去重，如作为发货记录的唯一索引
+func (m *Machine) OnTransition(hook Hook) {
+	m.mutex.Lock()
+	m.hooks = append(m.hooks, hook)
+	m.mutex.Unlock()
+}
+
+// SetLocale 设置错误信息的语言，可以是errs.LocaleZH(默认)或errs.LocaleEN，
+// 钩子和Store返回的错误也会被翻译，仍可以通过errors.Is/As判断原来的错误
+func (m *Machine) SetLocale(value string) error {
+	if err := errs.CheckLocale(value); err != nil {
+		return m.localize(err)
+	}
+	m.mutex.Lock()
+	m.locale = value
+	m.mutex.Unlock()
+	return nil
+}
+
+// ApplyNotify 应用已校验签名的异步通知，返回发生的转换，通知被忽略时返回nil
+func (m *Machine) ApplyNotify(params *notify.Params) (*Transition, error) {
+	if params == nil {
+		return nil, m.localize(errors.New("异步通知参数不能为nil"))
+	}
+	if params.TradeStatus == "" {
+		return nil, m.localize(errors.New("异步通知中缺少trade_status参数"))
+	}
+	// 只有退款通知带有refund_fee，且取值不小于0.01，其它通知中为0表示累计退款金额未知，由订单的当前状态确定
+	refundAmount := params.RefundFee
+	if refundAmount <= 0 {
+		refundAmount = -1
+	}
+	return m.apply(&Transition{
+		OutTradeNo:   params.OutTradeNo,
+		TradeNo:      params.TradeNo,
+		To:           params.TradeStatus,
+		TotalAmount:  params.TotalAmount,
+		RefundAmount: refundAmount,
+		Source:       SourceNotify,
+	})
+}
+
+// ApplyQuery 应用交易查询的结果，返回发生的转换，结果被忽略时返回nil
+// 交易查询不返回累计退款金额，因此只能识别全额退款导致的交易关闭，无法识别部分退款
+func (m *Machine) ApplyQuery(result *query.Result) (*Transition, error) {
+	if result == nil {
+		return nil, m.localize(errors.New("交易查询结果不能为nil"))
+	}
+	if result.TradeStatus == "" {
+		return nil, m.localize(errors.New("交易查询结果中缺少trade_status参数"))
+	}
+	var totalAmount float64
+	if result.TotalAmount != "" {
+		var err error
+		if totalAmount, err = strconv.ParseFloat(result.TotalAmount, 64); err != nil {
+			return nil, m.localize(errors.New("交易查询结果中的total_amount格式无效：" + result.TotalAmount))
+		}
+	}
+	return m.apply(&Transition{
+		OutTradeNo:   result.OutTradeNo,
+		TradeNo:      result.TradeNo,
+		To:           result.TradeStatus,
+		TotalAmount:  totalAmount,
+		RefundAmount: -1,
+		Source:       SourceQuery,
+	})
+}
+
+// 应用状态更新，t中的From、RefundDelta由订单的当前状态计算得到，RefundAmount为负数表示未知
+func (m *Machine) apply(t *Transition) (*Transition, error) {
+	if t.OutTradeNo == "" {
+		return nil, m.localize(errors.New("缺少商户订单号"))
+	}
+	switch t.To {
+	case StatusWaitBuyerPay, StatusTradeSuccess, StatusTradeFinished, StatusTradeClosed:
+	default:
+		return nil, m.localize(errors.New("无效的交易状态：" + t.To))
+	}
+
+	unlock := m.lock(t.OutTradeNo)
+	defer unlock()
+
+	order, err := m.store.Load(t.OutTradeNo)
+	if err != nil {
+		return nil, m.localize(err)
+	}
+	if order.Status == "" {
+		order.Status = StatusWaitBuyerPay
+	}
+	// 金额不一致说明通知与订单不匹配，不能更新订单状态
+	if t.TotalAmount != 0 && toCents(t.TotalAmount) != toCents(order.TotalAmount) {
+		return nil, m.localize(errors.New("订单" + order.OutTradeNo + "的金额与支付宝交易金额不一致"))
+	}
+	t.TotalAmount = order.TotalAmount
+	t.From = order.Status
+	if t.TradeNo == "" {
+		t.TradeNo = order.TradeNo
+	}
+
+	if t.RefundAmount < 0 {
+		t.RefundAmount = order.RefundAmount
+		// 已付款的交易关闭只可能是全额退款
+		if t.From == StatusTradeSuccess && t.To == StatusTradeClosed {
+			t.RefundAmount = order.TotalAmount
+		}
+	}
+	if toCents(t.RefundAmount) < toCents(order.RefundAmount) {
+		// 累计退款金额小于已记录的金额，是乱序到达的较早的通知
+		return nil, nil
+	}
+	t.RefundDelta = fromCents(toCents(t.RefundAmount) - toCents(order.RefundAmount))
+
+	if !legal(t) {
+		return nil, nil
+	}
+
+	m.mutex.Lock()
+	hooks := m.hooks
+	m.mutex.Unlock()
+	if err = m.store.Save(t, func() error {
+		for k := range hooks {
+			if err := hooks[k](t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		if err == ErrConflict {
+			return nil, nil
+		}
+		return nil, m.localize(err)
+	}
+	return t, nil
+}
+
+// 将err的错误信息翻译成设置的语言
+func (m *Machine) localize(err error) error {
+	m.mutex.Lock()
+	locale := m.locale
+	m.mutex.Unlock()
+	return errs.Localize(err, locale)
+}
+
+// 判断转换是否合法
+func legal(t *Transition) bool {
+	// 状态不变时只有退款金额增加才是转换
+	if t.From == t.To {
+		return t.To == StatusTradeSuccess && t.RefundDelta > 0
+	}
+	for _, to := range transitions[t.From] {
+		if to == t.To {
+			return true
+		}
+	}
+	return false
+}
+
+// 锁定单个订单，返回解锁函数
+func (m *Machine) lock(outTradeNo string) func() {
+	m.mutex.Lock()
+	l, exists := m.locks[outTradeNo]
+	if !exists {
+		l = &orderLock{}
+		m.locks[outTradeNo] = l
+	}
+	l.refs++
+	m.mutex.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.mutex.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(m.locks, outTradeNo)
+		}
+		m.mutex.Unlock()
+	}
+}
+
+// 将金额转换为分
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// 将分转换为金额
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
@@ -0,0 +1,202 @@
+package order
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/dxvgef/alipay/trade/query"
+	"github.com/dxvgef/alipay/trade/wap/notify"
+)
+
+var errTest = errors.New("测试错误")
+
+// 记录钩子调用的转换
+type testHook struct {
+	mutex sync.Mutex
+	keys  []string
+	err   error // 钩子返回的错误
+}
+
+func (h *testHook) call(t *Transition) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.keys = append(h.keys, t.Key())
+	return h.err
+}
+
+func (h *testHook) count() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.keys)
+}
+
+// Save返回错误的订单存储
+type failingStore struct {
+	*MemoryStore
+	err error
+}
+
+func (s *failingStore) Save(t *Transition, hooks func() error) error {
+	return s.err
+}
+
+// 创建带有一个待付款订单的状态机
+func newTestMachine(t *testing.T, store Store) (*Machine, *testHook) {
+	if s, ok := store.(*MemoryStore); ok {
+		if err := s.Add(&Order{OutTradeNo: "O1", TotalAmount: 10}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := New(store)
+	hook := &testHook{}
+	m.OnTransition(hook.call)
+	return m, hook
+}
+
+func paidNotify(refundFee float64) *notify.Params {
+	return &notify.Params{OutTradeNo: "O1", TradeNo: "T1", TradeStatus: StatusTradeSuccess, TotalAmount: 10, RefundFee: refundFee}
+}
+
+func loadOrder(t *testing.T, store *MemoryStore) *Order {
+	order, err := store.Load("O1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func TestDuplicateNotify(t *testing.T) {
+	store := NewMemoryStore()
+	m, hook := newTestMachine(t, store)
+
+	// 并发处理重复的付款通知，每个订单的锁保证只有一个转换
+	var wg sync.WaitGroup
+	for k := 0; k < 20; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.ApplyNotify(paidNotify(0)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if hook.count() != 1 {
+		t.Fatalf("期望钩子调用1次，得到%d次", hook.count())
+	}
+
+	transition, err := m.ApplyNotify(paidNotify(0))
+	if err != nil || transition != nil {
+		t.Fatalf("重复的通知应被忽略，得到%+v，%v", transition, err)
+	}
+	if order := loadOrder(t, store); order.Status != StatusTradeSuccess || order.TradeNo != "T1" {
+		t.Fatalf("订单状态错误：%+v", order)
+	}
+}
+
+func TestOutOfOrder(t *testing.T) {
+	store := NewMemoryStore()
+	m, hook := newTestMachine(t, store)
+
+	// 退款通知早于付款通知到达，同时视为付款和退款
+	transition, err := m.ApplyNotify(paidNotify(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transition == nil || !transition.Paid() || !transition.Refunded() || transition.RefundDelta != 3 {
+		t.Fatalf("期望付款并退款3元的转换，得到%+v", transition)
+	}
+	// 较晚到达的付款通知和累计金额更小的退款通知被忽略
+	for _, params := range []*notify.Params{paidNotify(0), paidNotify(1)} {
+		if transition, err = m.ApplyNotify(params); err != nil || transition != nil {
+			t.Fatalf("乱序的通知应被忽略，得到%+v，%v", transition, err)
+		}
+	}
+	// 交易查询结果中的全额退款关闭
+	transition, err = m.ApplyQuery(&query.Result{OutTradeNo: "O1", TradeStatus: StatusTradeClosed, TotalAmount: "10.00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transition == nil || !transition.Closed() || transition.RefundDelta != 7 {
+		t.Fatalf("期望全额退款关闭的转换，得到%+v", transition)
+	}
+	// 关闭后到达的查询结果和通知被忽略
+	if transition, err = m.ApplyQuery(&query.Result{OutTradeNo: "O1", TradeStatus: StatusTradeSuccess, TotalAmount: "10.00"}); err != nil || transition != nil {
+		t.Fatalf("交易关闭后的查询结果应被忽略，得到%+v，%v", transition, err)
+	}
+	if transition, err = m.ApplyNotify(&notify.Params{OutTradeNo: "O1", TradeStatus: StatusTradeFinished, TotalAmount: 10}); err != nil || transition != nil {
+		t.Fatalf("交易关闭后的通知应被忽略，得到%+v，%v", transition, err)
+	}
+
+	if hook.count() != 2 {
+		t.Fatalf("期望钩子调用2次，得到%d次", hook.count())
+	}
+	if order := loadOrder(t, store); order.Status != StatusTradeClosed || order.RefundAmount != 10 {
+		t.Fatalf("订单状态错误：%+v", order)
+	}
+}
+
+func TestAmountMismatch(t *testing.T) {
+	m, hook := newTestMachine(t, NewMemoryStore())
+	if _, err := m.ApplyNotify(&notify.Params{OutTradeNo: "O1", TradeStatus: StatusTradeSuccess, TotalAmount: 11}); err == nil {
+		t.Fatal("金额不一致的通知应返回错误")
+	}
+	if hook.count() != 0 {
+		t.Fatalf("金额不一致时不应调用钩子，得到%d次", hook.count())
+	}
+}
+
+func TestSaveFailure(t *testing.T) {
+	store := NewMemoryStore()
+	m, hook := newTestMachine(t, &failingStore{MemoryStore: store, err: errTest})
+	if err := store.Add(&Order{OutTradeNo: "O1", TotalAmount: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	transition, err := m.ApplyNotify(paidNotify(0))
+	if !errors.Is(err, errTest) || transition != nil {
+		t.Fatalf("期望Save的错误，得到%+v，%v", transition, err)
+	}
+	if hook.count() != 0 {
+		t.Fatalf("保存失败时不应调用钩子，得到%d次", hook.count())
+	}
+	if order := loadOrder(t, store); order.Status != StatusWaitBuyerPay {
+		t.Fatalf("保存失败时订单状态不应改变：%+v", order)
+	}
+}
+
+func TestHookFailure(t *testing.T) {
+	store := NewMemoryStore()
+	m, hook := newTestMachine(t, store)
+	hook.err = errTest
+
+	transition, err := m.ApplyNotify(paidNotify(0))
+	if !errors.Is(err, errTest) || transition != nil {
+		t.Fatalf("期望钩子的错误，得到%+v，%v", transition, err)
+	}
+	if order := loadOrder(t, store); order.Status != StatusWaitBuyerPay {
+		t.Fatalf("钩子失败时应撤销转换：%+v", order)
+	}
+
+	// 重发的通知再次应用转换并重新调用钩子，幂等键不变
+	hook.err = nil
+	if transition, err = m.ApplyNotify(paidNotify(0)); err != nil || transition == nil {
+		t.Fatalf("期望重新应用转换，得到%+v，%v", transition, err)
+	}
+	if hook.count() != 2 || hook.keys[0] != hook.keys[1] {
+		t.Fatalf("期望以相同的幂等键调用钩子2次，得到%q", hook.keys)
+	}
+	if order := loadOrder(t, store); order.Status != StatusTradeSuccess {
+		t.Fatalf("订单状态错误：%+v", order)
+	}
+}
+
+func TestTransitionKey(t *testing.T) {
+	paid := &Transition{OutTradeNo: "O1", From: StatusWaitBuyerPay, To: StatusTradeSuccess}
+	refund := &Transition{OutTradeNo: "O1", From: StatusTradeSuccess, To: StatusTradeSuccess, RefundAmount: 2.5, RefundDelta: 2.5}
+	refundAgain := &Transition{OutTradeNo: "O1", From: StatusTradeSuccess, To: StatusTradeSuccess, RefundAmount: 5, RefundDelta: 2.5}
+	if paid.Key() == refund.Key() || refund.Key() == refundAgain.Key() {
+		t.Fatalf("不同转换的幂等键应不同：%s、%s、%s", paid.Key(), refund.Key(), refundAgain.Key())
+	}
+}
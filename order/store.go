@@ -0,0 +1,96 @@
+package order
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrOrderNotFound 订单存储中不存在指定的订单
+var ErrOrderNotFound = errors.New("未找到订单")
+
+// ErrConflict 保存状态转换时订单的当前状态已不是转换前的状态，通常是其它进程已经处理了同一个转换
+var ErrConflict = errors.New("订单状态已被修改")
+
+// Store 订单状态存储，可使用数据库等实现持久化，实现必须可并发使用
+type Store interface {
+	// Load 读取订单，不存在时返回ErrOrderNotFound
+	Load(outTradeNo string) (*Order, error)
+	// Save 保存状态转换，必须以转换前的状态和累计退款金额为条件更新，
+	// 例如UPDATE ... WHERE status=? AND refund_amount=?，条件不满足时返回ErrConflict，
+	// 以保证多个进程同时处理同一个转换时只有一个能保存成功。
+	// 更新成功后调用hooks，hooks返回错误时必须撤销本次更新(例如回滚事务)并返回该错误，
+	// 使重发的通知能再次应用该转换并重新调用钩子
+	Save(t *Transition, hooks func() error) error
+}
+
+// MemoryStore 基于内存的订单存储
+type MemoryStore struct {
+	mutex  sync.RWMutex
+	orders map[string]Order
+}
+
+// NewMemoryStore 创建基于内存的订单存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		orders: make(map[string]Order),
+	}
+}
+
+// Add 添加订单，状态为空时视为StatusWaitBuyerPay
+func (s *MemoryStore) Add(order *Order) error {
+	if order == nil || order.OutTradeNo == "" {
+		return errors.New("订单的OutTradeNo不能为空")
+	}
+	o := *order
+	if o.Status == "" {
+		o.Status = StatusWaitBuyerPay
+	}
+	s.mutex.Lock()
+	s.orders[o.OutTradeNo] = o
+	s.mutex.Unlock()
+	return nil
+}
+
+// Load 读取订单
+func (s *MemoryStore) Load(outTradeNo string) (*Order, error) {
+	s.mutex.RLock()
+	order, exists := s.orders[outTradeNo]
+	s.mutex.RUnlock()
+	if !exists {
+		return nil, ErrOrderNotFound
+	}
+	return &order, nil
+}
+
+// Save 保存状态转换，hooks在释放存储的锁之后调用，返回错误时恢复转换前的订单
+func (s *MemoryStore) Save(t *Transition, hooks func() error) error {
+	s.mutex.Lock()
+	order, exists := s.orders[t.OutTradeNo]
+	if !exists {
+		s.mutex.Unlock()
+		return ErrOrderNotFound
+	}
+	if order.Status != t.From || toCents(order.RefundAmount) != toCents(t.RefundAmount-t.RefundDelta) {
+		s.mutex.Unlock()
+		return ErrConflict
+	}
+	saved := order
+	saved.Status = t.To
+	saved.RefundAmount = t.RefundAmount
+	if t.TradeNo != "" {
+		saved.TradeNo = t.TradeNo
+	}
+	s.orders[t.OutTradeNo] = saved
+	s.mutex.Unlock()
+
+	if err := hooks(); err != nil {
+		s.mutex.Lock()
+		// 只在订单仍是本次保存的状态时恢复，避免覆盖之后的转换
+		if s.orders[t.OutTradeNo] == saved {
+			s.orders[t.OutTradeNo] = order
+		}
+		s.mutex.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package reconcile
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/dxvgef/alipay/data/bill"
+	"github.com/dxvgef/alipay/errs"
+	"github.com/dxvgef/alipay/trade/wap/notify"
+)
+
+// 交易状态
+const (
+	StatusWaitBuyerPay  = "WAIT_BUYER_PAY" // 交易创建，等待买家付款
+	StatusTradeSuccess  = "TRADE_SUCCESS"  // 交易支付成功
+	StatusTradeFinished = "TRADE_FINISHED" // 交易结束，不可退款
+	StatusTradeClosed   = "TRADE_CLOSED"   // 未付款交易超时关闭，或支付完成后全额退款
+)
+
+// Order 本地订单
+type Order struct {
+	OutTradeNo   string  // 商户订单号
+	TradeNo      string  // 支付宝交易号，未付款时可以为空
+	Amount       float64 // 订单金额，单位为元
+	RefundAmount float64 // 累计退款金额，单位为元
+	Status       string  // 订单状态，使用支付宝的交易状态表示
+}
+
+// OrderSource 本地订单来源，由使用者基于自己的订单存储实现
+type OrderSource interface {
+	// Each 遍历对账周期内的所有本地订单，fn返回错误时应停止遍历并返回该错误
+	Each(fn func(*Order) error) error
+}
+
+// 支付宝侧的交易记录，由账单和异步通知汇总而来
+type alipayRecord struct {
+	outTradeNo   string
+	tradeNo      string
+	amount       float64
+	billRefund   float64         // 账单中退款行的合计金额
+	notifyRefund float64         // 异步通知中的累计退款金额
+	refundBatch  map[string]bool // 已累加的退款批次号，避免重复添加同一退款行
+	status       string
+	notified     bool // 是否收到过异步通知，只有账单时无法区分TRADE_SUCCESS和TRADE_FINISHED
+}
+
+// Reconciler 对账器，先添加账单明细和异步通知，再与本地订单对账
+type Reconciler struct {
+	records   map[string]*alipayRecord // 以商户订单号为键
+	byTradeNo map[string]*alipayRecord // 以支付宝交易号为键
+	locale    string                   // 错误信息的语言
+}
+
+// New 创建对账器
+func New() *Reconciler {
+	return &Reconciler{
+		records:   make(map[string]*alipayRecord),
+		byTradeNo: make(map[string]*alipayRecord),
+	}
+}
+
+// SetLocale 设置错误信息的语言，可以是errs.LocaleZH(默认)或errs.LocaleEN
+func (r *Reconciler) SetLocale(value string) error {
+	if err := errs.CheckLocale(value); err != nil {
+		return errs.Localize(err, r.locale)
+	}
+	r.locale = value
+	return nil
+}
+
+// AddTradeRow 添加业务账单中的一行，交易行记录支付金额，退款行累加退款金额，退款批次号相同的退款行只累加一次
+func (r *Reconciler) AddTradeRow(row *bill.TradeRow) error {
+	record, err := r.record(row.OutTradeNo, row.TradeNo)
+	if err != nil {
+		return err
+	}
+	switch row.BizType {
+	case "交易":
+		record.amount = row.TotalAmount
+		record.setStatus(StatusTradeSuccess)
+	case "退款":
+		if row.RefundBatchNo != "" {
+			if record.refundBatch[row.RefundBatchNo] {
+				return nil
+			}
+			if record.refundBatch == nil {
+				record.refundBatch = make(map[string]bool)
+			}
+			record.refundBatch[row.RefundBatchNo] = true
+		}
+		record.billRefund = fromCents(toCents(record.billRefund) + toCents(math.Abs(row.TotalAmount)))
+		if record.amount > 0 && toCents(record.refundAmount()) >= toCents(record.amount) {
+			record.setStatus(StatusTradeClosed)
+		}
+	}
+	return nil
+}
+
+// AddNotify 添加已校验签名的异步通知，同一订单的多次通知按交易状态的先后顺序合并，与到达顺序无关
+func (r *Reconciler) AddNotify(params *notify.Params) error {
+	record, err := r.record(params.OutTradeNo, params.TradeNo)
+	if err != nil {
+		return err
+	}
+	if params.TotalAmount > 0 {
+		record.amount = params.TotalAmount
+	}
+	// refund_fee是累计退款金额，与账单中的退款行分开记录，避免重复计算
+	if params.RefundFee > record.notifyRefund {
+		record.notifyRefund = params.RefundFee
+	}
+	record.setStatus(params.TradeStatus)
+	record.notified = true
+	return nil
+}
+
+// Reconcile 遍历本地订单并与已添加的支付宝记录对账，生成差异报告
+func (r *Reconciler) Reconcile(source OrderSource) (*Report, error) {
+	report := &Report{}
+	matched := make(map[*alipayRecord]bool)
+
+	err := source.Each(func(order *Order) error {
+		record := r.find(order)
+		if record == nil {
+			// 未付款或未付款关闭的本地订单在支付宝侧没有记录是正常的
+			if order.Status == StatusTradeSuccess || order.Status == StatusTradeFinished || order.RefundAmount > 0 {
+				report.add(KindMissingAlipay, order, nil)
+			}
+			return nil
+		}
+		matched[record] = true
+
+		consistent := true
+		// 只有退款记录时(交易在之前的账单中)，支付宝侧的订单金额未知
+		if record.amount != 0 && toCents(order.Amount) != toCents(record.amount) {
+			report.add(KindAmountDiff, order, record)
+			consistent = false
+		}
+		if toCents(order.RefundAmount) != toCents(record.refundAmount()) {
+			report.add(KindRefundDiff, order, record)
+			consistent = false
+		}
+		if !record.statusMatches(order.Status) {
+			report.add(KindStatusDiff, order, record)
+			consistent = false
+		}
+		if consistent {
+			report.Matched++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errs.Localize(err, r.locale)
+	}
+
+	for _, record := range r.records {
+		if !matched[record] {
+			report.add(KindMissingLocal, nil, record)
+		}
+	}
+	for _, record := range r.byTradeNo {
+		if record.outTradeNo == "" && !matched[record] {
+			report.add(KindMissingLocal, nil, record)
+		}
+	}
+
+	sort.SliceStable(report.Discrepancies, func(i, j int) bool {
+		a, b := report.Discrepancies[i], report.Discrepancies[j]
+		if a.OutTradeNo != b.OutTradeNo {
+			return a.OutTradeNo < b.OutTradeNo
+		}
+		if a.TradeNo != b.TradeNo {
+			return a.TradeNo < b.TradeNo
+		}
+		return a.Kind < b.Kind
+	})
+	return report, nil
+}
+
+// 获得或创建支付宝记录
+func (r *Reconciler) record(outTradeNo, tradeNo string) (*alipayRecord, error) {
+	if outTradeNo == "" && tradeNo == "" {
+		return nil, errs.Localize(errors.New("商户订单号和支付宝交易号不能同时为空"), r.locale)
+	}
+	record := r.records[outTradeNo]
+	if record == nil && tradeNo != "" {
+		record = r.byTradeNo[tradeNo]
+	}
+	if record == nil {
+		record = &alipayRecord{}
+	}
+	if record.outTradeNo == "" && outTradeNo != "" {
+		record.outTradeNo = outTradeNo
+		r.records[outTradeNo] = record
+	}
+	if record.tradeNo == "" && tradeNo != "" {
+		record.tradeNo = tradeNo
+		r.byTradeNo[tradeNo] = record
+	}
+	return record, nil
+}
+
+// 按商户订单号或支付宝交易号查找本地订单对应的支付宝记录
+func (r *Reconciler) find(order *Order) *alipayRecord {
+	if order.OutTradeNo != "" {
+		if record, exists := r.records[order.OutTradeNo]; exists {
+			return record
+		}
+	}
+	if order.TradeNo != "" {
+		if record, exists := r.byTradeNo[order.TradeNo]; exists {
+			return record
+		}
+	}
+	return nil
+}
+
+// 交易状态的先后顺序
+var statusRank = map[string]int{
+	StatusWaitBuyerPay:  1,
+	StatusTradeSuccess:  2,
+	StatusTradeFinished: 3,
+	StatusTradeClosed:   3,
+}
+
+// 获得累计退款金额，账单只包含对账周期内的退款，异步通知中是截至通知时的累计金额，取两者中较大的一个
+func (r *alipayRecord) refundAmount() float64 {
+	if r.notifyRefund > r.billRefund {
+		return r.notifyRefund
+	}
+	return r.billRefund
+}
+
+// 设置状态，只接受不早于当前状态的状态
+func (r *alipayRecord) setStatus(status string) {
+	if statusRank[status] == 0 {
+		return
+	}
+	if statusRank[status] >= statusRank[r.status] {
+		r.status = status
+	}
+}
+
+// 判断本地订单状态与支付宝交易状态是否一致
+func (r *alipayRecord) statusMatches(status string) bool {
+	if r.status == "" || status == r.status {
+		return true
+	}
+	// 只有账单数据时，已支付的交易可能已经结束
+	paid := func(s string) bool {
+		return s == StatusTradeSuccess || s == StatusTradeFinished
+	}
+	return !r.notified && paid(status) && paid(r.status)
+}
+
+// 将金额转换为分
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// 将分转换为金额
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
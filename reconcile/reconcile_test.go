@@ -0,0 +1,146 @@
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/dxvgef/alipay/data/bill"
+	"github.com/dxvgef/alipay/trade/wap/notify"
+)
+
+// 由切片实现的本地订单来源
+type testSource []Order
+
+func (s testSource) Each(fn func(*Order) error) error {
+	for k := range s {
+		if err := fn(&s[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 添加到对账器中的账单行或异步通知，二者只有一个不为nil
+type testInput struct {
+	row    *bill.TradeRow
+	notify *notify.Params
+}
+
+func tradeRow(amount float64) testInput {
+	return testInput{row: &bill.TradeRow{TradeNo: "T1", OutTradeNo: "O1", BizType: "交易", TotalAmount: amount}}
+}
+
+func refundRow(batchNo string, amount float64) testInput {
+	return testInput{row: &bill.TradeRow{TradeNo: "T1", OutTradeNo: "O1", BizType: "退款", TotalAmount: -amount, RefundBatchNo: batchNo}}
+}
+
+func notifyInput(status string, refundFee float64) testInput {
+	return testInput{notify: &notify.Params{TradeNo: "T1", OutTradeNo: "O1", TradeStatus: status, TotalAmount: 10, RefundFee: refundFee}}
+}
+
+func TestReconcileRefund(t *testing.T) {
+	cases := []struct {
+		name         string
+		inputs       []testInput
+		localRefund  float64
+		localStatus  string
+		alipayRefund float64 // 有差异时期望的支付宝累计退款金额
+		kinds        []string
+	}{
+		{
+			name:        "先通知后账单",
+			inputs:      []testInput{notifyInput(StatusTradeSuccess, 0), notifyInput(StatusTradeSuccess, 2.5), tradeRow(10), refundRow("R1", 2.5)},
+			localRefund: 2.5,
+			localStatus: StatusTradeSuccess,
+		},
+		{
+			name:        "先账单后通知",
+			inputs:      []testInput{tradeRow(10), refundRow("R1", 2.5), notifyInput(StatusTradeSuccess, 0), notifyInput(StatusTradeSuccess, 2.5)},
+			localRefund: 2.5,
+			localStatus: StatusTradeSuccess,
+		},
+		{
+			name:        "多次部分退款，通知只收到第一次",
+			inputs:      []testInput{tradeRow(10), notifyInput(StatusTradeSuccess, 2.5), refundRow("R1", 2.5), refundRow("R2", 3)},
+			localRefund: 5.5,
+			localStatus: StatusTradeSuccess,
+		},
+		{
+			name:        "重复添加同一退款行",
+			inputs:      []testInput{tradeRow(10), refundRow("R1", 2.5), refundRow("R1", 2.5)},
+			localRefund: 2.5,
+			localStatus: StatusTradeSuccess,
+		},
+		{
+			name:        "全额退款后交易关闭",
+			inputs:      []testInput{notifyInput(StatusTradeSuccess, 0), tradeRow(10), refundRow("R1", 10), notifyInput(StatusTradeClosed, 10)},
+			localRefund: 10,
+			localStatus: StatusTradeClosed,
+		},
+		{
+			name:         "本地未记录退款",
+			inputs:       []testInput{notifyInput(StatusTradeSuccess, 2.5), tradeRow(10), refundRow("R1", 2.5)},
+			localStatus:  StatusTradeSuccess,
+			alipayRefund: 2.5,
+			kinds:        []string{KindRefundDiff},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := New()
+			for _, input := range c.inputs {
+				var err error
+				if input.row != nil {
+					err = r.AddTradeRow(input.row)
+				} else {
+					err = r.AddNotify(input.notify)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			report, err := r.Reconcile(testSource{
+				{OutTradeNo: "O1", TradeNo: "T1", Amount: 10, RefundAmount: c.localRefund, Status: c.localStatus},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Discrepancies) != len(c.kinds) {
+				t.Fatalf("期望差异%v，得到%+v", c.kinds, report.Discrepancies)
+			}
+			for k := range c.kinds {
+				d := report.Discrepancies[k]
+				if d.Kind != c.kinds[k] || d.AlipayRefundAmount != c.alipayRefund {
+					t.Errorf("期望%s差异且支付宝退款金额为%.2f，得到%+v", c.kinds[k], c.alipayRefund, d)
+				}
+			}
+			if len(c.kinds) == 0 && report.Matched != 1 {
+				t.Errorf("期望1个一致的订单，得到%d个", report.Matched)
+			}
+		})
+	}
+}
+
+func TestReconcileMissing(t *testing.T) {
+	r := New()
+	if err := r.AddTradeRow(&bill.TradeRow{TradeNo: "T2", OutTradeNo: "O2", BizType: "交易", TotalAmount: 5}); err != nil {
+		t.Fatal(err)
+	}
+	report, err := r.Reconcile(testSource{
+		{OutTradeNo: "O1", Amount: 3, Status: StatusTradeSuccess},
+		{OutTradeNo: "O3", Amount: 3, Status: StatusWaitBuyerPay},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Discrepancies) != 2 ||
+		report.Discrepancies[0].Kind != KindMissingAlipay || report.Discrepancies[0].OutTradeNo != "O1" ||
+		report.Discrepancies[1].Kind != KindMissingLocal || report.Discrepancies[1].OutTradeNo != "O2" {
+		t.Fatalf("期望O1缺少支付宝记录、O2缺少本地订单，得到%+v", report.Discrepancies)
+	}
+}
+
+func TestReconcileEmptyNo(t *testing.T) {
+	if err := New().AddTradeRow(&bill.TradeRow{BizType: "交易"}); err == nil {
+		t.Fatal("商户订单号和支付宝交易号同时为空时应返回错误")
+	}
+}
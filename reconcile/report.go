@@ -0,0 +1,100 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// 差异类型
+const (
+	KindMissingLocal  = "missing_local"  // 支付宝有记录，本地没有订单
+	KindMissingAlipay = "missing_alipay" // 本地订单已支付，支付宝没有记录
+	KindAmountDiff    = "amount_diff"    // 订单金额不一致
+	KindRefundDiff    = "refund_diff"    // 退款金额不一致，包括本地未记录的退款
+	KindStatusDiff    = "status_diff"    // 交易状态不一致
+)
+
+// Discrepancy 一条对账差异
+type Discrepancy struct {
+	Kind               string  `json:"kind"`                 // 差异类型
+	OutTradeNo         string  `json:"out_trade_no"`         // 商户订单号
+	TradeNo            string  `json:"trade_no"`             // 支付宝交易号
+	LocalAmount        float64 `json:"local_amount"`         // 本地订单金额
+	AlipayAmount       float64 `json:"alipay_amount"`        // 支付宝订单金额
+	LocalRefundAmount  float64 `json:"local_refund_amount"`  // 本地累计退款金额
+	AlipayRefundAmount float64 `json:"alipay_refund_amount"` // 支付宝累计退款金额
+	LocalStatus        string  `json:"local_status"`         // 本地订单状态
+	AlipayStatus       string  `json:"alipay_status"`        // 支付宝交易状态
+}
+
+// Report 对账报告
+type Report struct {
+	Matched       int           `json:"matched"`       // 完全一致的订单数量
+	Discrepancies []Discrepancy `json:"discrepancies"` // 差异列表
+}
+
+// 添加一条差异，order和record可以有一个为nil
+func (r *Report) add(kind string, order *Order, record *alipayRecord) {
+	d := Discrepancy{
+		Kind: kind,
+	}
+	if order != nil {
+		d.OutTradeNo = order.OutTradeNo
+		d.TradeNo = order.TradeNo
+		d.LocalAmount = order.Amount
+		d.LocalRefundAmount = order.RefundAmount
+		d.LocalStatus = order.Status
+	}
+	if record != nil {
+		if d.OutTradeNo == "" {
+			d.OutTradeNo = record.outTradeNo
+		}
+		if d.TradeNo == "" {
+			d.TradeNo = record.tradeNo
+		}
+		d.AlipayAmount = record.amount
+		d.AlipayRefundAmount = record.refundAmount()
+		d.AlipayStatus = record.status
+	}
+	r.Discrepancies = append(r.Discrepancies, d)
+}
+
+// WriteJSON 以JSON格式输出对账报告
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV 以CSV格式输出差异列表，第一行为列名
+func (r *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"kind", "out_trade_no", "trade_no",
+		"local_amount", "alipay_amount",
+		"local_refund_amount", "alipay_refund_amount",
+		"local_status", "alipay_status",
+	}); err != nil {
+		return err
+	}
+	for k := range r.Discrepancies {
+		d := r.Discrepancies[k]
+		if err := writer.Write([]string{
+			d.Kind, d.OutTradeNo, d.TradeNo,
+			formatAmount(d.LocalAmount), formatAmount(d.AlipayAmount),
+			formatAmount(d.LocalRefundAmount), formatAmount(d.AlipayRefundAmount),
+			d.LocalStatus, d.AlipayStatus,
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// 格式化金额，保留两位小数
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
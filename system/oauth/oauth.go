@@ -0,0 +1,69 @@
+package oauth
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
+)
+
+// 用户信息授权页面地址
+const AuthorizeURL = "https://openauth.alipay.com/oauth2/publicAppAuthorize.htm"
+
+// 授权范围
+const (
+	ScopeAuthBase = "auth_base" // 静默授权，只能获取用户的UserID
+	ScopeAuthUser = "auth_user" // 主动授权，可获取用户的基础信息
+)
+
+// Redirect 用户授权后支付宝回跳到redirect_uri时携带的参数
+type Redirect struct {
+	AppID    string // 应用ID
+	AuthCode string // 授权码，用于换取访问令牌，只能使用一次
+	Scope    string // 用户授权的范围
+	State    string // 构建授权链接时传入的自定义参数，原样返回
+}
+
+// BuildAuthorizeURL 构建用户授权链接，scopes可以是ScopeAuthBase、ScopeAuthUser中的一个或多个，
+// state为可选的自定义参数，建议传入随机值用于防止CSRF攻击
+func BuildAuthorizeURL(appID, redirectURI, state string, scopes ...string) (string, error) {
+	if appID == "" {
+		return "", errs.Invalid("appID", errs.RuleRequired, "AppID不能为空")
+	}
+	if redirectURI == "" {
+		return "", errs.Invalid("redirectURI", errs.RuleRequired, "redirectURI不能为空")
+	}
+	if len(scopes) == 0 {
+		return "", errs.Invalid("scopes", errs.RuleRequired, "授权范围不能为空")
+	}
+	for k := range scopes {
+		if scopes[k] != ScopeAuthBase && scopes[k] != ScopeAuthUser {
+			return "", errs.Invalid("scopes", errs.RuleEnum, "授权范围只能是auth_base或auth_user")
+		}
+	}
+	values := make(url.Values)
+	values.Set("app_id", appID)
+	values.Set("scope", strings.Join(scopes, ","))
+	values.Set("redirect_uri", redirectURI)
+	if state != "" {
+		values.Set("state", state)
+	}
+	return AuthorizeURL + "?" + values.Encode(), nil
+}
+
+// ParseRedirect 解析授权回跳请求中的参数，错误信息使用alipayConfig设置的语言
+func ParseRedirect(alipayConfig *config.Config, req *http.Request) (*Redirect, error) {
+	query := req.URL.Query()
+	redirect := &Redirect{
+		AppID:    query.Get("app_id"),
+		AuthCode: query.Get("auth_code"),
+		Scope:    query.Get("scope"),
+		State:    query.Get("state"),
+	}
+	if redirect.AuthCode == "" {
+		return nil, alipayConfig.Localize(errs.Invalid("auth_code", errs.RuleRequired, "授权回跳参数中缺少auth_code"))
+	}
+	return redirect, nil
+}
@@ -0,0 +1,95 @@
+package oauth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+)
+
+// 换取和刷新访问令牌的接口名称
+const tokenMethod = "alipay.system.oauth.token"
+
+// Token 用户访问令牌
+type Token struct {
+	UserID       string     `json:"user_id"`       // 支付宝用户的唯一标识
+	OpenID       string     `json:"open_id"`       // 支付宝用户在应用下的唯一标识
+	AccessToken  string     `json:"access_token"`  // 访问令牌，用于获取用户信息
+	ExpiresIn    int64      `json:"expires_in"`    // 访问令牌的有效时间，单位为秒
+	RefreshToken string     `json:"refresh_token"` // 刷新令牌
+	ReExpiresIn  int64      `json:"re_expires_in"` // 刷新令牌的有效时间，单位为秒
+	AuthStart    model.Time `json:"auth_start"`    // 授权开始时间
+	CreatedAt    time.Time  `json:"created_at"`    // 令牌的获取时间
+}
+
+// 获得令牌有效期的起始时间，优先使用支付宝返回的授权开始时间
+func (t *Token) startTime() time.Time {
+	if !t.AuthStart.IsZero() {
+		return t.AuthStart.Time
+	}
+	return t.CreatedAt
+}
+
+// ExpiresAt 获得访问令牌的过期时间
+func (t *Token) ExpiresAt() time.Time {
+	return t.startTime().Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
+// RefreshExpiresAt 获得刷新令牌的过期时间
+func (t *Token) RefreshExpiresAt() time.Time {
+	return t.startTime().Add(time.Duration(t.ReExpiresIn) * time.Second)
+}
+
+// Expired 判断访问令牌在leeway时间之后是否已过期
+func (t *Token) Expired(leeway time.Duration) bool {
+	return !time.Now().Add(leeway).Before(t.ExpiresAt())
+}
+
+// 接口响应参数
+type tokenResponse struct {
+	gateway.Response
+	Token
+}
+
+// ExchangeToken 使用授权码换取访问令牌
+func ExchangeToken(alipayConfig *config.Config, authCode string) (*Token, error) {
+	if authCode == "" {
+		return nil, alipayConfig.Localize(errs.Invalid("authCode", errs.RuleRequired, "授权码不能为空"))
+	}
+	return requestToken(alipayConfig, map[string]string{
+		"grant_type": "authorization_code",
+		"code":       authCode,
+	})
+}
+
+// RefreshToken 使用刷新令牌换取新的访问令牌
+func RefreshToken(alipayConfig *config.Config, refreshToken string) (*Token, error) {
+	if refreshToken == "" {
+		return nil, alipayConfig.Localize(errs.Invalid("refreshToken", errs.RuleRequired, "刷新令牌不能为空"))
+	}
+	return requestToken(alipayConfig, map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+}
+
+// 请求令牌接口，该接口的参数是公共请求参数而不是biz_content
+func requestToken(alipayConfig *config.Config, params map[string]string) (*Token, error) {
+	var resp tokenResponse
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method: tokenMethod,
+		Params: params,
+	}, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.AccessToken == "" {
+		return nil, alipayConfig.Localize(errors.New("支付宝未返回访问令牌"))
+	}
+	token := resp.Token
+	token.CreatedAt = time.Now()
+	return &token, nil
+}
@@ -0,0 +1,44 @@
+package oauth
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
+	"github.com/dxvgef/alipay/gateway"
+)
+
+// 获取用户信息的接口名称
+const userInfoMethod = "alipay.user.info.share"
+
+// UserInfo 支付宝用户信息，需要用户以auth_user范围授权
+type UserInfo struct {
+	UserID   string `json:"user_id"`   // 支付宝用户的唯一标识
+	OpenID   string `json:"open_id"`   // 支付宝用户在应用下的唯一标识
+	Avatar   string `json:"avatar"`    // 用户头像地址
+	NickName string `json:"nick_name"` // 用户昵称
+	Province string `json:"province"`  // 省份名称
+	City     string `json:"city"`      // 市名称
+	Gender   string `json:"gender"`    // 性别，F：女性，M：男性
+}
+
+// 接口响应参数
+type userInfoResponse struct {
+	gateway.Response
+	UserInfo
+}
+
+// GetUserInfo 使用访问令牌获取支付宝用户信息
+func GetUserInfo(alipayConfig *config.Config, accessToken string) (*UserInfo, error) {
+	if accessToken == "" {
+		return nil, alipayConfig.Localize(errs.Invalid("accessToken", errs.RuleRequired, "访问令牌不能为空"))
+	}
+	var resp userInfoResponse
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method: userInfoMethod,
+		Params: map[string]string{
+			"auth_token": accessToken,
+		},
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.UserInfo, nil
+}
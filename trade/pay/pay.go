@@ -0,0 +1,68 @@
+package pay
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 统一收单交易支付接口名称
+const payMethod = "alipay.trade.pay"
+
+// 销售产品码
+const (
+	ProductGeneralWithholding = "GENERAL_WITHHOLDING" // 商户代扣
+	ProductCyclePayAuth       = "CYCLE_PAY_AUTH"      // 周期扣款
+	ProductPreAuthOnline      = "PRE_AUTH_ONLINE"     // 资金授权冻结转支付
+)
+
+// BizContent 统一收单交易支付请求参数，用于协议代扣和资金授权冻结转支付
+type BizContent struct {
+	OutTradeNo      string                 `json:"out_trade_no" validate:"required,max=64"`                                                          // 必填，商户订单号，64个字符以内
+	TotalAmount     float64                `json:"total_amount" validate:"range=0.01-100000000"`                                                     // 必填，订单总金额，单位为元，精确到小数点后两位，取值范围[0.01,100000000]
+	Subject         string                 `json:"subject" validate:"required,maxrunes=256"`                                                         // 必填，订单标题
+	ProductCode     string                 `json:"product_code" validate:"required,enum=GENERAL_WITHHOLDING|CYCLE_PAY_AUTH|PRE_AUTH_ONLINE"`         // 必填，销售产品码，GENERAL_WITHHOLDING、CYCLE_PAY_AUTH或PRE_AUTH_ONLINE
+	Body            string                 `json:"body,omitempty"`                                                                                   // 订单附加信息
+	GoodsDetail     []model.GoodsDetail    `json:"goods_detail,omitempty"`                                                                           // 订单包含的商品列表信息
+	AgreementParams *model.AgreementParams `json:"agreement_params,omitempty" validate:"required_if=ProductCode GENERAL_WITHHOLDING|CYCLE_PAY_AUTH"` // 代扣协议参数，协议代扣时必填
+	AuthNo          string                 `json:"auth_no,omitempty" validate:"required_if=ProductCode PRE_AUTH_ONLINE"`                             // 资金授权订单号，冻结转支付时必填
+	AuthConfirmMode string                 `json:"auth_confirm_mode,omitempty" validate:"enum=COMPLETE|NOT_COMPLETE"`                                // 冻结转支付后剩余冻结资金的处理方式，COMPLETE：自动解冻，NOT_COMPLETE：不自动解冻
+	BuyerID         string                 `json:"buyer_id,omitempty"`                                                                               // 买家的支付宝用户ID，冻结转支付时可以传入
+	SellerID        string                 `json:"seller_id,omitempty"`                                                                              // 卖家的支付宝用户ID，为空时默认为商户签约账号对应的支付宝用户ID
+	StoreID         string                 `json:"store_id,omitempty"`                                                                               // 商户门店编号
+	TimeoutExpress  string                 `json:"timeout_express,omitempty" validate:"duration"`                                                    // 该笔订单允许的最晚付款时间，取值范围：1m～15d
+	RoyaltyInfo     *model.RoyaltyInfo     `json:"royalty_info,omitempty"`                                                                           // 描述分账信息
+	SettleInfo      *model.SettleInfo      `json:"settle_info,omitempty"`                                                                            // 描述结算信息
+}
+
+// Result 统一收单交易支付结果
+type Result struct {
+	gateway.Response
+	TradeNo        string           `json:"trade_no"`         // 支付宝交易号
+	OutTradeNo     string           `json:"out_trade_no"`     // 商户订单号
+	BuyerLogonID   string           `json:"buyer_logon_id"`   // 买家支付宝账号
+	BuyerUserID    string           `json:"buyer_user_id"`    // 买家在支付宝的用户ID
+	TotalAmount    string           `json:"total_amount"`     // 交易金额，单位为元
+	ReceiptAmount  string           `json:"receipt_amount"`   // 实收金额，单位为元
+	BuyerPayAmount string           `json:"buyer_pay_amount"` // 买家付款的金额，单位为元
+	GmtPayment     model.Time       `json:"gmt_payment"`      // 交易支付时间
+	FundBillList   []model.FundBill `json:"fund_bill_list"`   // 交易支付使用的资金渠道
+}
+
+// Pay 发起统一收单交易支付，使用代扣协议扣款或将冻结的资金转为支付
+func Pay(alipayConfig *config.Config, notifyURL string, bizContent *BizContent) (*Result, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
+	var result Result
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     payMethod,
+		NotifyURL:  notifyURL,
+		BizContent: bizContent,
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,57 @@
+package query
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 统一收单线下交易查询接口名称
+const queryMethod = "alipay.trade.query"
+
+// BizContent 交易查询请求参数，OutTradeNo与TradeNo至少传入一个，同时传入时以TradeNo为准
+type BizContent struct {
+	OutTradeNo   string   `json:"out_trade_no,omitempty" validate:"required_without=TradeNo"` // 商户订单号
+	TradeNo      string   `json:"trade_no,omitempty"`                                         // 支付宝交易号
+	OrgPID       string   `json:"org_pid,omitempty"`                                          // 银行间联模式下有用，其它场景请不要使用
+	QueryOptions []string `json:"query_options,omitempty"`                                    // 查询选项，指定需要额外返回的信息，如fund_bill_list、voucher_detail_list
+}
+
+// Result 交易查询结果
+type Result struct {
+	gateway.Response
+	TradeNo        string           `json:"trade_no"`         // 支付宝交易号
+	OutTradeNo     string           `json:"out_trade_no"`     // 商户订单号
+	BuyerLogonID   string           `json:"buyer_logon_id"`   // 买家支付宝账号
+	BuyerUserID    string           `json:"buyer_user_id"`    // 买家在支付宝的用户ID
+	TradeStatus    string           `json:"trade_status"`     // 交易状态，WAIT_BUYER_PAY、TRADE_CLOSED、TRADE_SUCCESS或TRADE_FINISHED
+	TotalAmount    string           `json:"total_amount"`     // 交易的订单金额，单位为元
+	BuyerPayAmount string           `json:"buyer_pay_amount"` // 买家实付金额，单位为元
+	PointAmount    string           `json:"point_amount"`     // 积分支付的金额，单位为元
+	InvoiceAmount  string           `json:"invoice_amount"`   // 交易中用户支付的可开具发票的金额，单位为元
+	ReceiptAmount  string           `json:"receipt_amount"`   // 实收金额，单位为元
+	SendPayDate    model.Time       `json:"send_pay_date"`    // 本次交易打款给卖家的时间
+	StoreID        string           `json:"store_id"`         // 商户门店编号
+	StoreName      string           `json:"store_name"`       // 请求交易支付中的商户店铺的名称
+	TerminalID     string           `json:"terminal_id"`      // 商户机具终端编号
+	Subject        string           `json:"subject"`          // 订单标题
+	Body           string           `json:"body"`             // 订单描述
+	FundBillList   []model.FundBill `json:"fund_bill_list"`   // 交易支付使用的资金渠道
+}
+
+// Query 查询交易的状态和金额
+func Query(alipayConfig *config.Config, bizContent *BizContent) (*Result, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
+	var result Result
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     queryMethod,
+		BizContent: bizContent,
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
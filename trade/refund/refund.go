@@ -0,0 +1,52 @@
+package refund
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 统一收单交易退款接口名称
+const refundMethod = "alipay.trade.refund"
+
+// BizContent 交易退款请求参数，OutTradeNo与TradeNo至少传入一个，同时传入时以TradeNo为准
+type BizContent struct {
+	OutTradeNo   string              `json:"out_trade_no,omitempty" validate:"required_without=TradeNo"` // 商户订单号
+	TradeNo      string              `json:"trade_no,omitempty"`                                         // 支付宝交易号
+	RefundAmount float64             `json:"refund_amount" validate:"range=0.01-100000000"`              // 必填，需要退款的金额，单位为元，不能大于订单金额
+	RefundReason string              `json:"refund_reason,omitempty" validate:"maxrunes=256"`            // 退款的原因说明
+	OutRequestNo string              `json:"out_request_no,omitempty" validate:"max=64"`                 // 退款请求号，标识一次退款请求，部分退款时必填，同一笔交易多次退款时不能重复
+	GoodsDetail  []model.GoodsDetail `json:"goods_detail,omitempty"`                                     // 退款包含的商品列表信息，按商品退款时使用
+	QueryOptions []string            `json:"query_options,omitempty"`                                    // 查询选项，指定需要额外返回的信息，如refund_detail_item_list
+}
+
+// Result 交易退款结果
+type Result struct {
+	gateway.Response
+	TradeNo              string           `json:"trade_no"`                // 支付宝交易号
+	OutTradeNo           string           `json:"out_trade_no"`            // 商户订单号
+	BuyerLogonID         string           `json:"buyer_logon_id"`          // 买家支付宝账号
+	BuyerUserID          string           `json:"buyer_user_id"`           // 买家在支付宝的用户ID
+	FundChange           string           `json:"fund_change"`             // 本次退款是否发生了资金变化，Y或N
+	RefundFee            string           `json:"refund_fee"`              // 该笔交易已退款的总金额，单位为元
+	SendBackFee          string           `json:"send_back_fee"`           // 本次商户实际退回金额，单位为元
+	GmtRefundPay         model.Time       `json:"gmt_refund_pay"`          // 退款支付时间
+	RefundDetailItemList []model.FundBill `json:"refund_detail_item_list"` // 退款使用的资金渠道
+}
+
+// Refund 发起交易退款，按商品退款时可以先用model.CheckGoodsAmount检查商品金额的合计是否等于退款金额
+func Refund(alipayConfig *config.Config, bizContent *BizContent) (*Result, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
+	var result Result
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     refundMethod,
+		BizContent: bizContent,
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,100 @@
+package royalty
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 分账关系接口名称
+const (
+	bindMethod       = "alipay.trade.royalty.relation.bind"
+	unbindMethod     = "alipay.trade.royalty.relation.unbind"
+	batchQueryMethod = "alipay.trade.royalty.relation.batchquery"
+)
+
+// Receiver 分账收款方
+type Receiver struct {
+	Type          string `json:"type" validate:"required,enum=userId|loginName|openId"` // 必填，收款方账户类型，userId、loginName或openId
+	Account       string `json:"account" validate:"required_without=AccountOpenID"`     // 必填，收款方账户，类型为userId时为2088开头的支付宝用户ID，为loginName时为支付宝登录号
+	AccountOpenID string `json:"account_open_id,omitempty"`                             // 收款方账户的OpenID，类型为openId时使用
+	Name          string `json:"name,omitempty" validate:"required_if=Type loginName"`  // 收款方全称，类型为loginName时必填，用于校验
+	Memo          string `json:"memo,omitempty"`                                        // 分账关系描述
+	LoginName     string `json:"login_name,omitempty"`                                  // 收款方的支付宝登录号，查询结果中返回
+	BindLoginName string `json:"bind_login_name,omitempty"`                             // 收款方绑定的支付宝登录号，查询结果中返回
+}
+
+// RelationBizContent 绑定或解绑分账关系的请求参数
+type RelationBizContent struct {
+	ReceiverList []Receiver `json:"receiver_list" validate:"required,max=20"`  // 必填，分账收款方列表，单次最多20个
+	OutRequestNo string     `json:"out_request_no" validate:"required,max=32"` // 必填，外部请求号，由商家自定义，32个字符以内
+}
+
+// RelationResult 绑定或解绑分账关系的结果
+type RelationResult struct {
+	gateway.Response
+	ResultCode string `json:"result_code"` // 业务结果，SUCCESS：成功，FAIL：失败
+}
+
+// BatchQueryBizContent 查询分账关系的请求参数
+type BatchQueryBizContent struct {
+	PageNum      int    `json:"page_num,omitempty" validate:"min=0"`        // 页码，从1开始，默认为1
+	PageSize     int    `json:"page_size,omitempty" validate:"range=0-100"` // 每页记录数，默认为10，最大为100
+	OutRequestNo string `json:"out_request_no" validate:"required,max=32"`  // 必填，外部请求号，由商家自定义，32个字符以内
+}
+
+// BatchQueryResult 查询分账关系的结果
+type BatchQueryResult struct {
+	gateway.Response
+	ResultCode      string     `json:"result_code"`       // 业务结果，SUCCESS：成功，FAIL：失败
+	ReceiverList    []Receiver `json:"receiver_list"`     // 分账收款方列表
+	TotalPageNum    int        `json:"total_page_num"`    // 总页数
+	TotalRecordNum  int        `json:"total_record_num"`  // 总记录数
+	CurrentPageNum  int        `json:"current_page_num"`  // 当前页码
+	CurrentPageSize int        `json:"current_page_size"` // 当前页记录数
+}
+
+// Bind 绑定分账关系，分账前需要先绑定收款方
+func Bind(alipayConfig *config.Config, bizContent *RelationBizContent, options ...gateway.Option) (*RelationResult, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+	var result RelationResult
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     bindMethod,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Unbind 解绑分账关系
+func Unbind(alipayConfig *config.Config, bizContent *RelationBizContent, options ...gateway.Option) (*RelationResult, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+	var result RelationResult
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     unbindMethod,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BatchQuery 分页查询已绑定的分账关系
+func BatchQuery(alipayConfig *config.Config, bizContent *BatchQueryBizContent, options ...gateway.Option) (*BatchQueryResult, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+	var result BatchQueryResult
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     batchQueryMethod,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,57 @@
+package settle
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 交易分账查询接口名称
+const queryMethod = "alipay.trade.order.settle.query"
+
+// QueryBizContent 交易分账查询请求参数，SettleNo与OutRequestNo+TradeNo至少传入一组
+type QueryBizContent struct {
+	SettleNo     string `json:"settle_no,omitempty"`                                           // 支付宝分账单号
+	OutRequestNo string `json:"out_request_no,omitempty" validate:"required_without=SettleNo"` // 结算请求流水号
+	TradeNo      string `json:"trade_no,omitempty" validate:"required_without=SettleNo"`       // 支付宝交易号
+}
+
+// RoyaltyDetailResult 分账明细的执行结果
+type RoyaltyDetailResult struct {
+	OperationType string     `json:"operation_type"` // 分账操作类型，replenish：补差，replenish_refund：退补差，transfer：分账，transfer_refund：退分账
+	ExecuteDt     model.Time `json:"execute_dt"`     // 分账执行时间
+	TransOut      string     `json:"trans_out"`      // 分账支出方账号
+	TransOutType  string     `json:"trans_out_type"` // 分账支出方账户类型
+	TransIn       string     `json:"trans_in"`       // 分账收入方账号
+	TransInType   string     `json:"trans_in_type"`  // 分账收入方账户类型
+	Amount        string     `json:"amount"`         // 分账金额，单位为元
+	State         string     `json:"state"`          // 分账状态，SUCCESS：成功，FAIL：失败，PROCESSING：处理中
+	DetailID      string     `json:"detail_id"`      // 分账明细单号
+	ErrorCode     string     `json:"error_code"`     // 分账失败时的错误代码
+	ErrorDesc     string     `json:"error_desc"`     // 分账失败时的错误描述
+}
+
+// QueryResult 交易分账查询结果
+type QueryResult struct {
+	gateway.Response
+	OutRequestNo      string                `json:"out_request_no"`      // 结算请求流水号
+	OperationDt       model.Time            `json:"operation_dt"`        // 分账受理时间
+	RoyaltyDetailList []RoyaltyDetailResult `json:"royalty_detail_list"` // 分账明细
+}
+
+// Query 查询分账的执行结果
+func Query(alipayConfig *config.Config, bizContent *QueryBizContent, options ...gateway.Option) (*QueryResult, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
+	var result QueryResult
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     queryMethod,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
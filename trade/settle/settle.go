@@ -0,0 +1,55 @@
+package settle
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 统一收单交易结算接口名称
+const settleMethod = "alipay.trade.order.settle"
+
+// 分账模式
+const (
+	ModeSync  = "sync"  // 同步执行分账，默认值
+	ModeAsync = "async" // 异步执行分账，结果需要通过分账查询获得
+)
+
+// BizContent 统一收单交易结算请求参数，用于对支付时冻结了资金(royalty_freeze)的交易进行分账
+type BizContent struct {
+	OutRequestNo      string                `json:"out_request_no" validate:"required,max=32"`         // 必填，结算请求流水号，由商家自定义，32个字符以内
+	TradeNo           string                `json:"trade_no" validate:"required"`                      // 必填，支付宝交易号
+	RoyaltyParameters []model.RoyaltyDetail `json:"royalty_parameters" validate:"required"`            // 必填，分账明细信息
+	OperatorID        string                `json:"operator_id,omitempty"`                             // 操作员ID
+	ExtendParams      *ExtendParams         `json:"extend_params,omitempty"`                           // 扩展参数
+	RoyaltyMode       string                `json:"royalty_mode,omitempty" validate:"enum=sync|async"` // 分账模式，sync或async，默认为sync
+}
+
+// ExtendParams 结算扩展参数
+type ExtendParams struct {
+	RoyaltyFinish string `json:"royalty_finish,omitempty" validate:"enum=true|false"` // 是否完结分账，true表示本次分账后解冻交易中剩余的冻结资金，不能再进行分账
+}
+
+// Result 统一收单交易结算结果
+type Result struct {
+	gateway.Response
+	TradeNo  string `json:"trade_no"`  // 支付宝交易号
+	SettleNo string `json:"settle_no"` // 支付宝分账单号，可以用于分账查询
+}
+
+// Settle 对交易进行分账结算
+func Settle(alipayConfig *config.Config, bizContent *BizContent, options ...gateway.Option) (*Result, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
+	var result Result
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     settleMethod,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/dxvgef/alipay/model"
+)
+
+// 代扣协议通知类型
+const (
+	TypeUserSign   = "dut_user_sign"   // 用户签约成功通知
+	TypeUserUnsign = "dut_user_unsign" // 用户解约通知
+)
+
+// 代扣协议通知参数
+type AgreementParams struct {
+	AgreementNo         string     // 支付宝系统中用以唯一标识用户签约记录的编号
+	ExternalAgreementNo string     // 商户签约号
+	PersonalProductCode string     // 个人签约产品码
+	SignScene           string     // 协议签约场景
+	Status              string     // 协议状态，NORMAL：正常，UNSIGN：已解约
+	SignTime            model.Time // 协议签约时间
+	SignModifyTime      model.Time // 协议修改时间，解约通知中为解约时间
+	ValidTime           model.Time // 协议生效时间
+	InvalidTime         model.Time // 协议失效时间
+	AlipayUserID        string     // 用户的支付宝账号对应的支付宝唯一用户号
+	AlipayLogonID       string     // 用户的支付宝登录账号
+	ExternalLogonID     string     // 用户在商户网站的登录账号
+	PartnerID           string     // 签约的商户PID
+	ZmOpenID            string     // 用户的芝麻信用openId
+	CreditAuthMode      string     // 授信模式
+	UnsignType          string     // 解约类型，仅在解约通知中返回
+}
+
+// IsAgreement 判断是否是代扣协议签约或解约通知
+func (obj *Params) IsAgreement() bool {
+	return strings.HasPrefix(obj.NotifyType, "dut_user_")
+}
+
+// 解析代扣协议通知参数
+func parseAgreementParams(values url.Values) (*AgreementParams, error) {
+	params := AgreementParams{
+		AgreementNo:         values.Get("agreement_no"),
+		ExternalAgreementNo: values.Get("external_agreement_no"),
+		PersonalProductCode: values.Get("personal_product_code"),
+		SignScene:           values.Get("sign_scene"),
+		Status:              values.Get("status"),
+		AlipayUserID:        values.Get("alipay_user_id"),
+		AlipayLogonID:       values.Get("alipay_logon_id"),
+		ExternalLogonID:     values.Get("external_logon_id"),
+		PartnerID:           values.Get("partner_id"),
+		ZmOpenID:            values.Get("zm_open_id"),
+		CreditAuthMode:      values.Get("credit_auth_mode"),
+		UnsignType:          values.Get("unsign_type"),
+	}
+	times := []struct {
+		name  string
+		value *model.Time
+	}{
+		{"sign_time", &params.SignTime},
+		{"sign_modify_time", &params.SignModifyTime},
+		{"valid_time", &params.ValidTime},
+		{"invalid_time", &params.InvalidTime},
+	}
+	for k := range times {
+		var err error
+		if *times[k].value, err = model.ParseTime(values.Get(times[k].name)); err != nil {
+			return nil, err
+		}
+	}
+	return &params, nil
+}
@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dxvgef/alipay/model"
+)
+
+// 异步通知类型
+const (
+	TypeTradeStatusSync  = "trade_status_sync"  // 交易状态通知
+	TypeFundAuthFreeze   = "fund_auth_freeze"   // 资金授权冻结通知
+	TypeFundAuthUnfreeze = "fund_auth_unfreeze" // 资金授权解冻通知
+)
+
+// 资金授权通知参数
+type FundAuthParams struct {
+	AuthNo              string     // 支付宝资金授权订单号
+	OutOrderNo          string     // 商户授权资金订单号
+	OperationID         string     // 支付宝资金操作流水号
+	OutRequestNo        string     // 商户本次资金操作的请求流水号
+	OperationType       string     // 资金操作类型，FREEZE：冻结，UNFREEZE：解冻，PAY：支付
+	Amount              float64    // 本次操作的金额，单位为元
+	Status              string     // 资金操作流水的状态，INIT：初始，SUCCESS：成功，CLOSED：关闭
+	GmtCreate           model.Time // 操作创建时间
+	GmtTrans            model.Time // 处理成功时间
+	PayerLogonID        string     // 付款方支付宝账号登录号
+	PayerUserID         string     // 付款方支付宝用户号
+	PayeeLogonID        string     // 收款方支付宝账号登录号
+	PayeeUserID         string     // 收款方支付宝用户号
+	TotalFreezeAmount   float64    // 订单累计的冻结金额，单位为元
+	TotalUnfreezeAmount float64    // 订单累计的解冻金额，单位为元
+	TotalPayAmount      float64    // 订单累计用于支付的金额，单位为元
+	RestAmount          float64    // 订单总共剩余的冻结金额，单位为元
+	CreditAmount        float64    // 本次操作中信用金额，单位为元
+	FundAmount          float64    // 本次操作中自有资金金额，单位为元
+	PreAuthType         string     // 预授权类型，CREDIT_AUTH表示信用预授权
+	TransCurrency       string     // 标价币种
+}
+
+// IsFundAuth 判断是否是资金授权通知
+func (obj *Params) IsFundAuth() bool {
+	return strings.HasPrefix(obj.NotifyType, "fund_auth")
+}
+
+// 解析资金授权通知参数
+func parseFundAuthParams(values url.Values) (*FundAuthParams, error) {
+	var err error
+	var params FundAuthParams
+	params.AuthNo = values.Get("auth_no")
+	params.OutOrderNo = values.Get("out_order_no")
+	params.OperationID = values.Get("operation_id")
+	params.OutRequestNo = values.Get("out_request_no")
+	params.OperationType = values.Get("operation_type")
+	params.Status = values.Get("status")
+	if params.GmtCreate, err = model.ParseTime(values.Get("gmt_create")); err != nil {
+		return nil, err
+	}
+	if params.GmtTrans, err = model.ParseTime(values.Get("gmt_trans")); err != nil {
+		return nil, err
+	}
+	params.PayerLogonID = values.Get("payer_logon_id")
+	params.PayerUserID = values.Get("payer_user_id")
+	params.PayeeLogonID = values.Get("payee_logon_id")
+	params.PayeeUserID = values.Get("payee_user_id")
+	params.PreAuthType = values.Get("pre_auth_type")
+	params.TransCurrency = values.Get("trans_currency")
+	amounts := []struct {
+		name  string
+		value *float64
+	}{
+		{"amount", &params.Amount},
+		{"total_freeze_amount", &params.TotalFreezeAmount},
+		{"total_unfreeze_amount", &params.TotalUnfreezeAmount},
+		{"total_pay_amount", &params.TotalPayAmount},
+		{"rest_amount", &params.RestAmount},
+		{"credit_amount", &params.CreditAmount},
+		{"fund_amount", &params.FundAmount},
+	}
+	for k := range amounts {
+		if values.Get(amounts[k].name) == "" {
+			continue
+		}
+		if *amounts[k].value, err = strconv.ParseFloat(values.Get(amounts[k].name), 64); err != nil {
+			return nil, err
+		}
+	}
+	return &params, nil
+}
@@ -1,41 +1,121 @@
 package notify
 
 import (
-	"crypto"
-	"crypto/rsa"
-	"crypto/sha1"
-	"crypto/sha256"
-	"encoding/base64"
+	"encoding/json"
 	"errors"
-	"hash"
 	"net/http"
 	"net/url"
 	"sort"
 	"strings"
 
+	"github.com/dxvgef/alipay/charset"
 	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
 )
 
-// 校验异步通知的签名
+// 校验异步通知的签名，返回的错误信息使用配置的语言
 func Verity(alipayConfig *config.Config, req *http.Request) (*Params, error) {
 	if err := req.ParseForm(); err != nil {
 		return nil, err
 	}
 
+	// 按通知的charset参数将参数值转换成UTF-8
+	values, err := decodeValues(req.PostForm)
+	if err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
+	// 解密加密的业务参数
+	values, err = decryptValues(alipayConfig, values)
+	if err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
 	// 解析异步通知参数到结构体
-	params, err := parseNotifyParams(req)
+	params, err := parseNotifyParams(values)
 	if err != nil {
-		return nil, err
+		return nil, alipayConfig.Localize(err)
 	}
 
-	// 校验签名
+	// 校验签名，支付宝按通知声明的编码签名，因此使用未转换的原始参数
 	if err := veritySign(req.PostForm, alipayConfig); err != nil {
-		return nil, err
+		return nil, alipayConfig.Localize(err)
 	}
 
 	return params, nil
 }
 
+// 从多商户配置注册表中按通知的app_id找到对应的配置，再校验异步通知的签名
+func VerityByRegistry(registry *config.Registry, req *http.Request) (*Params, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	alipayConfig, err := registry.Get(req.PostForm.Get("app_id"))
+	if err != nil {
+		return nil, err
+	}
+
+	return Verity(alipayConfig, req)
+}
+
+// 按charset参数将参数值转换成UTF-8，返回参数副本，不会修改原参数
+func decodeValues(values url.Values) (url.Values, error) {
+	name := values.Get("charset")
+	result := make(url.Values, len(values))
+	for k := range values {
+		result[k] = make([]string, len(values[k]))
+		for i := range values[k] {
+			value, err := charset.Decode(name, values[k][i])
+			if err != nil {
+				return nil, err
+			}
+			result[k][i] = value
+		}
+	}
+	return result, nil
+}
+
+// 解密业务参数，当通知启用了AES加密时，将biz_content解密后的字段合并到参数副本中，不会修改原参数
+func decryptValues(alipayConfig *config.Config, values url.Values) (url.Values, error) {
+	if values.Get("encrypt_type") != "AES" || values.Get("biz_content") == "" {
+		return values, nil
+	}
+
+	plainText, err := alipayConfig.Decrypt(values.Get("biz_content"))
+	if err != nil {
+		return nil, errs.Wrap(err, "异步通知的biz_content解密失败："+err.Error())
+	}
+	// 明文使用通知声明的编码
+	plainStr, err := charset.Decode(values.Get("charset"), string(plainText))
+	if err != nil {
+		return nil, err
+	}
+	plainText = []byte(plainStr)
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(plainText, &fields); err != nil {
+		return nil, errors.New("异步通知的biz_content格式无效：" + err.Error())
+	}
+
+	result := make(url.Values, len(values)+len(fields))
+	for k := range values {
+		result[k] = values[k]
+	}
+	for k := range fields {
+		if result.Get(k) != "" {
+			continue
+		}
+		// 字符串字段取其值，其它字段保留原始JSON
+		var str string
+		if json.Unmarshal(fields[k], &str) == nil {
+			result.Set(k, str)
+		} else {
+			result.Set(k, string(fields[k]))
+		}
+	}
+	return result, nil
+}
+
 // 校验签名
 func veritySign(values url.Values, alipayConfig *config.Config) error {
 	// 解析参数
@@ -57,34 +137,9 @@ func veritySign(values url.Values, alipayConfig *config.Config) error {
 
 	data := strings.Join(querySlice, "&")
 
-	// 验证签名
-	signData, err := base64.StdEncoding.DecodeString(sign)
-	if err != nil {
-		return err
-	}
-
-	// rsa校验
-	var h hash.Hash
-	var hType crypto.Hash
-
-	switch alipayConfig.GetAppSignType() {
-	case "RSA":
-		h = sha1.New()
-		hType = crypto.SHA1
-	case "RSA2":
-		h = sha256.New()
-		hType = crypto.SHA256
-	default:
-		return errors.New("仅支持RSA(SHA1)和RSA2(SHA256)两种签名算法")
+	// 验证签名，通知携带了已缓存的支付宝公钥证书SN时使用该证书，否则依次尝试所有缓存的支付宝公钥
+	if sn := values.Get("alipay_cert_sn"); sn != "" && alipayConfig.GetAlipayPublicKeyBySN(sn) != nil {
+		return alipayConfig.VerifySign([]byte(data), sign, sn)
 	}
-
-	if _, err := h.Write([]byte(data)); err != nil {
-		return err
-	}
-	err = rsa.VerifyPKCS1v15(alipayConfig.GetAlipayPublicKey(), hType, h.Sum(nil), signData)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return alipayConfig.VerifySignByAnyKey([]byte(data), sign)
 }
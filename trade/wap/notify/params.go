@@ -2,14 +2,15 @@ package notify
 
 import (
 	"encoding/json"
-	"net/http"
 	"net/url"
 	"strconv"
+
+	"github.com/dxvgef/alipay/model"
 )
 
 // 异步通知参数
 type Params struct {
-	NotifyTime        string              // 通知的发送时间，格式为yyyy-MM-dd HH:mm:ss
+	NotifyTime        model.Time          // 通知的发送时间
 	NotifyType        string              // 通知的类型
 	NotifyID          string              // 通知校验ID
 	AppID             string              // 支付宝分配给开发者的应用ID
@@ -33,13 +34,24 @@ type Params struct {
 	RefundFee         float64             // 退款通知中，返回总退款金额，单位为元，取值范围为[0.01，100000000.00]，精确到小数点后两位
 	Subject           string              // 商品的标题/交易标题/订单标题/订单关键字等，是请求时对应的参数，原样通知回来
 	Body              string              // 该订单的备注、描述、明细等。对应请求时的body参数，原样通知回来
-	GmtCreate         string              // 该笔交易创建的时间。格式为yyyy-MM-dd HH:mm:ss
-	GmtPayment        string              // 该笔交易的买家付款时间。格式为yyyy-MM-dd HH:mm:ss
-	GmtRefund         string              // 该笔交易的退款时间。格式为yyyy-MM-dd HH:mm:ss
-	GmtClose          string              // 该笔交易结束时间。格式为yyyy-MM-dd HH:mm:ss
+	GmtCreate         model.Time          // 该笔交易创建的时间
+	GmtPayment        model.Time          // 该笔交易的买家付款时间
+	GmtRefund         model.Time          // 该笔交易的退款时间
+	GmtClose          model.Time          // 该笔交易结束时间
 	FundBillList      []FundBillList      // 支付成功的各个渠道金额信息，详见资金明细信息说明
-	PassbackParams    string              // 公共回传参数，如果请求时传递了该参数，则返回给商户时会在异步通知时将该参数原样返回，本参数必须进行UrlEncode之后才可以发送给支付宝
+	PassbackParams    string              // 公共回传参数，如果请求时传递了该参数，则返回给商户时会在异步通知时将该参数原样返回，这里保存通知中的原始值，解码后的值使用DecodedPassbackParams获得
 	VoucherDetailList []VoucherDetailList // 本交易支付时所使用的所有优惠券信息，详见优惠券信息说明
+	FundAuth          *FundAuthParams     // 资金授权通知参数，仅在资金授权冻结、解冻通知中有值
+	Agreement         *AgreementParams    // 代扣协议通知参数，仅在签约、解约通知中有值
+}
+
+// DecodedPassbackParams 返回URL解码后的公共回传参数，原始值不是有效的URL编码时返回原始值
+func (obj *Params) DecodedPassbackParams() string {
+	value, err := url.QueryUnescape(obj.PassbackParams)
+	if err != nil {
+		return obj.PassbackParams
+	}
+	return value
 }
 
 // 支付渠道信息
@@ -59,79 +71,98 @@ type VoucherDetailList struct {
 }
 
 // 解析异步通知参数到结构体
-func parseNotifyParams(req *http.Request) (*Params, error) {
+func parseNotifyParams(values url.Values) (*Params, error) {
 	var err error
 	var params Params
-	params.NotifyTime = req.PostFormValue("notify_time")
-	params.NotifyType = req.PostFormValue("notify_type")
-	params.NotifyID = req.PostFormValue("notify_id")
-	params.AppID = req.PostFormValue("app_id")
-	params.Charset = req.PostFormValue("charset")
-	params.Version = req.PostFormValue("version")
-	params.SignType = req.PostFormValue("sign_type")
-	params.Sign = req.PostFormValue("sign")
-	params.TradeNo = req.PostFormValue("trade_no")
-	params.OutTradeNo = req.PostFormValue("out_trade_no")
-	params.OutBizNo = req.PostFormValue("out_biz_no")
-	params.BuyerID = req.PostFormValue("buyer_id")
-	params.BuyerLogonID = req.PostFormValue("buyer_logon_id")
-	params.SellerID = req.PostFormValue("seller_id")
-	params.SellerEmail = req.PostFormValue("seller_email")
-	params.TradeStatus = req.PostFormValue("trade_status")
-	if req.PostFormValue("total_amount") != "" {
-		params.TotalAmount, err = strconv.ParseFloat(req.PostFormValue("total_amount"), 64)
+	if params.NotifyTime, err = model.ParseTime(values.Get("notify_time")); err != nil {
+		return nil, err
+	}
+	params.NotifyType = values.Get("notify_type")
+	params.NotifyID = values.Get("notify_id")
+	params.AppID = values.Get("app_id")
+	params.Charset = values.Get("charset")
+	params.Version = values.Get("version")
+	params.SignType = values.Get("sign_type")
+	params.Sign = values.Get("sign")
+	params.TradeNo = values.Get("trade_no")
+	params.OutTradeNo = values.Get("out_trade_no")
+	params.OutBizNo = values.Get("out_biz_no")
+	params.BuyerID = values.Get("buyer_id")
+	params.BuyerLogonID = values.Get("buyer_logon_id")
+	params.SellerID = values.Get("seller_id")
+	params.SellerEmail = values.Get("seller_email")
+	params.TradeStatus = values.Get("trade_status")
+	if values.Get("total_amount") != "" {
+		params.TotalAmount, err = strconv.ParseFloat(values.Get("total_amount"), 64)
 		if err != nil {
 			return nil, err
 		}
 	}
-	if req.PostFormValue("receipt_amount") != "" {
-		params.ReceiptAmount, err = strconv.ParseFloat(req.PostFormValue("receipt_amount"), 64)
+	if values.Get("receipt_amount") != "" {
+		params.ReceiptAmount, err = strconv.ParseFloat(values.Get("receipt_amount"), 64)
 		if err != nil {
 			return nil, err
 		}
 	}
-	if req.PostFormValue("invoice_amount") != "" {
-		params.InvoiceAmount, err = strconv.ParseFloat(req.PostFormValue("invoice_amount"), 64)
+	if values.Get("invoice_amount") != "" {
+		params.InvoiceAmount, err = strconv.ParseFloat(values.Get("invoice_amount"), 64)
 		if err != nil {
 			return nil, err
 		}
 	}
-	if req.PostFormValue("buyer_pay_amount") != "" {
-		params.BuyerPayAmount, err = strconv.ParseFloat(req.PostFormValue("buyer_pay_amount"), 64)
+	if values.Get("buyer_pay_amount") != "" {
+		params.BuyerPayAmount, err = strconv.ParseFloat(values.Get("buyer_pay_amount"), 64)
 		if err != nil {
 			return nil, err
 		}
 	}
-	if req.PostFormValue("point_amount") != "" {
-		params.PointAmount, err = strconv.ParseFloat(req.PostFormValue("point_amount"), 64)
+	if values.Get("point_amount") != "" {
+		params.PointAmount, err = strconv.ParseFloat(values.Get("point_amount"), 64)
 		if err != nil {
 			return nil, err
 		}
 	}
-	if req.PostFormValue("refund_fee") != "" {
-		params.RefundFee, err = strconv.ParseFloat(req.PostFormValue("refund_fee"), 64)
+	if values.Get("refund_fee") != "" {
+		params.RefundFee, err = strconv.ParseFloat(values.Get("refund_fee"), 64)
 		if err != nil {
 			return nil, err
 		}
 	}
-	params.Subject = req.PostFormValue("subject")
-	params.Body = req.PostFormValue("body")
-	params.GmtCreate = req.PostFormValue("gmt_create")
-	params.GmtPayment = req.PostFormValue("gmt_payment")
-	params.GmtRefund = req.PostFormValue("gmt_refund")
-	params.GmtClose = req.PostFormValue("gmt_close")
-	if req.PostFormValue("fund_bill_list") != "" {
-		if err := json.Unmarshal([]byte(req.PostFormValue("fund_bill_list")), &params.FundBillList); err != nil {
+	params.Subject = values.Get("subject")
+	params.Body = values.Get("body")
+	times := []struct {
+		name  string
+		value *model.Time
+	}{
+		{"gmt_create", &params.GmtCreate},
+		{"gmt_payment", &params.GmtPayment},
+		{"gmt_refund", &params.GmtRefund},
+		{"gmt_close", &params.GmtClose},
+	}
+	for k := range times {
+		if *times[k].value, err = model.ParseTime(values.Get(times[k].name)); err != nil {
 			return nil, err
 		}
 	}
-	if req.PostFormValue("passback_params") != "" {
-		if params.PassbackParams, err = url.QueryUnescape(req.PostFormValue("passback_params")); err != nil {
+	if values.Get("fund_bill_list") != "" {
+		if err := json.Unmarshal([]byte(values.Get("fund_bill_list")), &params.FundBillList); err != nil {
+			return nil, err
+		}
+	}
+	params.PassbackParams = values.Get("passback_params")
+	if values.Get("voucher_detail_list") != "" {
+		if err := json.Unmarshal([]byte(values.Get("voucher_detail_list")), &params.VoucherDetailList); err != nil {
+			return nil, err
+		}
+	}
+
+	if params.IsFundAuth() {
+		if params.FundAuth, err = parseFundAuthParams(values); err != nil {
 			return nil, err
 		}
 	}
-	if req.PostFormValue("voucher_detail_list") != "" {
-		if err := json.Unmarshal([]byte(req.PostFormValue("voucher_detail_list")), &params.VoucherDetailList); err != nil {
+	if params.IsAgreement() {
+		if params.Agreement, err = parseAgreementParams(values); err != nil {
 			return nil, err
 		}
 	}
@@ -2,294 +2,275 @@ package pay
 
 import (
 	"encoding/json"
-	"errors"
-	"net/url"
-	"strconv"
+	"sort"
 	"time"
 
+	"github.com/dxvgef/alipay/charset"
 	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/errs"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
 )
 
 // API请求地址
-const APIURL = "https://openapi.alipay.com/gateway.do"
+const APIURL = gateway.APIURL
 
-// Params 公共请求参数
+// Params 公共请求参数，签名时由网关按配置中的AppID、签名类型和证书SN构建公共参数，Format固定为JSON，Version固定为1.0，
+// 因此AppID、SignType、AppCertSN和AlipayRootCertSN必须与配置一致，否则Validate和SignByCert返回校验错误
 type Params struct {
 	alipayConfig     *config.Config // 支付宝应用配置
 	AppCertSN        string         // 应用公钥证书SN
 	AlipayRootCertSN string         // 支付宝根证书SN
-	AppID            string         // 必填，支付宝分配给开发者的应用ID
+	AppID            string         `validate:"required,numeric"` // 必填，支付宝分配给开发者的应用ID
 	Method           string         // 必填，接口名称
-	Format           string         // 仅支持"JSON"
-	ReturnURL        string         // HTTP/HTTPS开头的URL字符串
-	Charset          string         // 必填，请求使用的编码格式，如utf-8,gbk,gb2312等
-	SignType         string         // 必填，商户生成签名字符串所使用的签名算法类型，目前支持RSA2和RSA，推荐使用RSA2
-	Timestamp        string         // 必填，发送请求的时间，格式"yyyy-MM-dd HH:mm:ss"
-	Version          string         // 必填，调用的接口版本，固定为：1.0
-	NotifyURL        string         // 支付宝服务器主动通知商户服务器里指定的页面http/https路径。
+	Format           string         `validate:"enum=JSON"`                      // 仅支持"JSON"
+	ReturnURL        string         `validate:"url,max=256"`                    // HTTP/HTTPS开头的URL字符串
+	Charset          string         `validate:"required,enum=utf-8|gbk|gb2312"` // 必填，请求使用的编码格式，支持utf-8、gbk和gb2312，请求参数会按该编码转换后再签名
+	SignType         string         `validate:"required,enum=RSA|RSA2|SM2"`     // 必填，商户生成签名字符串所使用的签名算法类型，目前支持RSA2、RSA和SM2，推荐使用RSA2
+	Timestamp        model.Time     `validate:"required"`                       // 必填，发送请求的时间，按Asia/Shanghai时区以"yyyy-MM-dd HH:mm:ss"格式发送
+	Version          string         `validate:"required,enum=1|1.0"`            // 必填，调用的接口版本，固定为：1.0
+	NotifyURL        string         `validate:"url,max=256"`                    // 支付宝服务器主动通知商户服务器里指定的页面http/https路径。
 	AppAuthToken     string         // 详见应用授权概述
 	BizContent       *BizContent
-	sign             string // 签名字符串
-	paramsStr        string // 请求参数拼接成的字符串
-	urlValues        url.Values
+	request          *gateway.Request // 签名时构建的网关请求参数
+	paramsStr        string           // 待签名的参数字符串
+	pageURL          string           // 已签名的支付链接
+	autoTruncate     bool             // 校验前自动截断超长的Subject和Body
+	checkGoods       bool             // 校验商品明细的合计金额是否等于订单总金额
 }
 
 // BizContent 请求参数
 type BizContent struct {
-	AuthToken          string        `json:"auth_token,omitempty"`           // 针对用户授权接口，获取用户相关数据时，用于标识用户授权关系
-	Body               string        `json:"body,omitempty"`                 // 商品说明
-	BusinessParams     string        `json:"business_params,omitempty"`      // 商户传入业务信息，具体值要和支付宝约定，应用于安全，营销等参数直传场景，格式为json格式
-	DisablePayChannels string        `json:"disable_pay_channels,omitempty"` // 禁用渠道，用户不可用指定渠道支付，当有多个渠道时用“,”分隔，与enable_pay_channels互斥
-	EnablePayChannels  string        `json:"enable_pay_channels,omitempty"`  // 可用渠道，用户只能在指定渠道范围内支付，当有多个渠道时用“,”分隔，与disable_pay_channels互斥
-	ExtendParams       *ExtendParams `json:"extend_params,omitempty"`        // 业务扩展参数
-	ExtUserInfo        *ExtUserInfo  `json:"ext_user_info,omitempty"`        // 外部指定买家
-	GoodsType          string        `json:"goods_type,omitempty"`           // 商品主类型 :0-虚拟类商品,1-实物类商品
-	MerchantOrderNo    string        `json:"merchant_order_no,omitempty"`    // 商户原始订单号，最大长度限制32位
-	OutTradeNo         string        `json:"out_trade_no"`                   // 本地订单号
-	PassbackParams     string        `json:"passback_params,omitempty"`      // 公用回传参数，如果请求时传递了该参数，则返回给商户时会回传该参数。支付宝只会在同步返回（包括跳转回商户网站）和异步通知时将该参数原样返回。本参数必须进行UrlEncode之后才可以发送给支付宝。
-	ProductCode        string        `json:"product_code"`                   // 销售产品码，商家和支付宝签约的产品码，移动网站支付2.0的值是UICK_WAP_WAY
-	PromoParams        string        `json:"promo_params,omitempty"`         // 优惠参数，仅与支付宝协商后可用
-	QuitURL            string        `json:"quit_url,omitempty"`             // 用户付款中途退出返回商户网站的地址
-	SpecifiedChannel   string        `json:"specified_channel,omitempty"`    // 指定渠道，目前仅支持传入pcredit，若由于用户原因渠道不可用，用户可选择是否用其他渠道支付
-	StoreID            string        `json:"store_id,omitempty"`             // 商户门店编号
-	Subject            string        `json:"subject"`                        // 商品标题
-	TimeExpire         string        `json:"time_expire,omitempty"`          // 绝对超时时间，格式为yyyy-MM-dd HH:mm
-	TimeoutExpress     string        `json:"timeout_express,omitempty"`      // 该笔订单允许的最晚付款时间，逾期将关闭交易。取值范围：1m～15d。m-分钟，h-小时，d-天，1c-当天（1c-当天的情况下，无论交易何时创建，都在0点关闭）。 该参数数值不接受小数点， 如 1.5h，可转换为 90m。
-	TotalAmount        float32       `json:"total_amount"`                   // 订单总金额，单位为元，精确到小数点后两位
+	AgreementSignParams *model.AgreementSignParams `json:"agreement_sign_params,omitempty"`                                               // 签约参数，支付并签约代扣协议时使用
+	AuthToken           string                     `json:"auth_token,omitempty"`                                                          // 针对用户授权接口，获取用户相关数据时，用于标识用户授权关系
+	Body                string                     `json:"body,omitempty" validate:"maxrunes=128"`                                        // 商品说明
+	BusinessParams      *BusinessParams            `json:"business_params,omitempty"`                                                     // 商户传入的业务信息，应用于安全、营销等参数直传场景，以JSON字符串发送
+	DisablePayChannels  string                     `json:"disable_pay_channels,omitempty" validate:"max=128"`                             // 禁用渠道，用户不可用指定渠道支付，当有多个渠道时用“,”分隔，与enable_pay_channels互斥
+	EnablePayChannels   string                     `json:"enable_pay_channels,omitempty" validate:"max=128,exclusive=DisablePayChannels"` // 可用渠道，用户只能在指定渠道范围内支付，当有多个渠道时用“,”分隔，与disable_pay_channels互斥
+	ExtendParams        *ExtendParams              `json:"extend_params,omitempty"`                                                       // 业务扩展参数
+	ExtUserInfo         *ExtUserInfo               `json:"ext_user_info,omitempty"`                                                       // 外部指定买家
+	GoodsDetail         []model.GoodsDetail        `json:"goods_detail,omitempty"`                                                        // 订单包含的商品列表信息
+	InvoiceInfo         *InvoiceInfo               `json:"invoice_info,omitempty"`                                                        // 开票信息
+	GoodsType           string                     `json:"goods_type,omitempty" validate:"enum=0|1"`                                      // 商品主类型 :0-虚拟类商品,1-实物类商品
+	MerchantOrderNo     string                     `json:"merchant_order_no,omitempty" validate:"max=32"`                                 // 商户原始订单号，最大长度限制32位
+	OutTradeNo          string                     `json:"out_trade_no" validate:"required,max=64"`                                       // 本地订单号
+	PassbackParams      string                     `json:"passback_params,omitempty" validate:"max=512"`                                  // 公用回传参数，如果请求时传递了该参数，则返回给商户时会回传该参数。支付宝只会在同步返回（包括跳转回商户网站）和异步通知时将该参数原样返回。本参数必须进行UrlEncode之后才可以发送给支付宝。
+	ProductCode         string                     `json:"product_code" validate:"required,enum=QUICK_WAP_WAY"`                           // 销售产品码，商家和支付宝签约的产品码，移动网站支付2.0的值是UICK_WAP_WAY
+	PromoParams         string                     `json:"promo_params,omitempty" validate:"max=512,json"`                                // 优惠参数，仅与支付宝协商后可用
+	QuitURL             string                     `json:"quit_url,omitempty" validate:"max=400"`                                         // 用户付款中途退出返回商户网站的地址
+	RoyaltyInfo         *model.RoyaltyInfo         `json:"royalty_info,omitempty"`                                                        // 描述分账信息
+	SettleInfo          *model.SettleInfo          `json:"settle_info,omitempty"`                                                         // 描述结算信息
+	SpecifiedChannel    string                     `json:"specified_channel,omitempty"`                                                   // 指定渠道，目前仅支持传入pcredit，若由于用户原因渠道不可用，用户可选择是否用其他渠道支付
+	SubMerchant         *SubMerchant               `json:"sub_merchant,omitempty"`                                                        // 间连受理商户信息体，当前只对特殊银行机构特定场景下使用此字段
+	StoreID             string                     `json:"store_id,omitempty"`                                                            // 商户门店编号
+	Subject             string                     `json:"subject" validate:"required,maxrunes=256"`                                      // 商品标题
+	TimeExpire          *model.MinuteTime          `json:"time_expire,omitempty"`                                                         // 绝对超时时间，按Asia/Shanghai时区以yyyy-MM-dd HH:mm格式发送
+	TimeoutExpress      string                     `json:"timeout_express,omitempty" validate:"duration"`                                 // 该笔订单允许的最晚付款时间，逾期将关闭交易。取值范围：1m～15d。m-分钟，h-小时，d-天，1c-当天（1c-当天的情况下，无论交易何时创建，都在0点关闭）。 该参数数值不接受小数点， 如 1.5h，可转换为 90m。
+	TotalAmount         float32                    `json:"total_amount" validate:"range=0.01-100000000"`                                  // 订单总金额，单位为元，精确到小数点后两位
+	Extra               map[string]interface{}     `json:"-" validate:"-"`                                                                // 额外的参数，序列化时合并到biz_content中，用于发送尚未在结构体中定义的新参数，不能与已赋值的字段重名
 }
 
 // ExtendParams // 业务扩展参数
 type ExtendParams struct {
-	HbFqNum              string `json:"hb_fq_num,omitempty"`               // 花呗分期数（目前仅支持3、6、12）注：使用该参数需要仔细阅读“花呗分期接入文档”
-	HbFqSellerPercent    string `json:"hb_fq_seller_percent,omitempty"`    // 使用花呗分期卖家承担收费比例，商家承担手续费传入100，用户承担手续费传入0，仅支持传入100、0两种，其他比例暂不支持注：使用该参数需要仔细阅读“花呗分期接入文档”
-	NeedBuyerRealnamed   string `json:"need_buyer_realnamed,omitempty"`    // 是否发起实名校验T：发起F：不发起
-	SysServiceProviderID string `json:"sys_service_provider_id,omitempty"` // 系统商编号，该参数作为系统商返佣数据提取的依据，请填写系统商签约协议的PID
-	TransMemo            string `json:"trans_memo,omitempty"`              // 账务备注：该字段显示在离线账单的账务备注中
+	HbFqNum              string `json:"hb_fq_num,omitempty" validate:"enum=3|6|12"`           // 花呗分期数（目前仅支持3、6、12）注：使用该参数需要仔细阅读“花呗分期接入文档”
+	HbFqSellerPercent    string `json:"hb_fq_seller_percent,omitempty" validate:"enum=0|100"` // 使用花呗分期卖家承担收费比例，商家承担手续费传入100，用户承担手续费传入0，仅支持传入100、0两种，其他比例暂不支持注：使用该参数需要仔细阅读“花呗分期接入文档”
+	NeedBuyerRealnamed   string `json:"need_buyer_realnamed,omitempty" validate:"tf"`         // 是否发起实名校验T：发起F：不发起
+	RoyaltyFreeze        string `json:"royalty_freeze,omitempty" validate:"enum=true|false"`  // 是否进行资金冻结，用于后续分账，true表示冻结，false或不传表示不冻结
+	SysServiceProviderID string `json:"sys_service_provider_id,omitempty" validate:"max=64"`  // 系统商编号，该参数作为系统商返佣数据提取的依据，请填写系统商签约协议的PID
+	TransMemo            string `json:"trans_memo,omitempty" validate:"maxrunes=128"`         // 账务备注：该字段显示在离线账单的账务备注中
 }
 
 // ExtUserInfo 外部指定买家
 type ExtUserInfo struct {
-	CertNo        string `json:"cert_no,omitempty"`         // 证件号，need_check_info=T时该参数才有效
-	CertType      string `json:"cert_type,omitempty"`       // need_check_info=T时该参数才有效。身份证：IDENTITY_CARD、护照：PASSPORT、军官证：OFFICER_CARD、士兵证：SOLDIER_CARD、户口本：HOKOU等。如有其它类型需要支持，请与蚂蚁金服工作人员联系
-	MinAge        string `json:"min_age,omitempty"`         // 允许的最小买家年龄，买家年龄必须大于等于所传数值，need_check_info=T时该参数才有效，min_age为整数，必须大于等于0
-	Mobile        string `json:"mobile,omitempty"`          // 手机号，该参数暂不校验
-	Name          string `json:"name,omitempty"`            // 姓名，need_check_info=T时该参数才有效
-	NeedCheckInfo string `json:"need_check_info,omitempty"` // 是否强制校验身份信息，T:强制校验 / F：不强制
-	FixBuyer      string `json:"fix_buyer,omitempty"`       // 是否强制校验付款人身份信息，T:强制校验 / F：不强制
+	CertNo        string `json:"cert_no,omitempty" validate:"max=64"`        // 证件号，need_check_info=T时该参数才有效
+	CertType      string `json:"cert_type,omitempty" validate:"max=32"`      // need_check_info=T时该参数才有效。身份证：IDENTITY_CARD、护照：PASSPORT、军官证：OFFICER_CARD、士兵证：SOLDIER_CARD、户口本：HOKOU等。如有其它类型需要支持，请与蚂蚁金服工作人员联系
+	MinAge        string `json:"min_age,omitempty" validate:"uint"`          // 允许的最小买家年龄，买家年龄必须大于等于所传数值，need_check_info=T时该参数才有效，min_age为整数，必须大于等于0
+	Mobile        string `json:"mobile,omitempty"`                           // 手机号，该参数暂不校验
+	Name          string `json:"name,omitempty" validate:"maxrunes=16"`      // 姓名，need_check_info=T时该参数才有效
+	NeedCheckInfo string `json:"need_check_info,omitempty" validate:"tf"`    // 是否强制校验身份信息，T:强制校验 / F：不强制
+	FixBuyer      string `json:"fix_buyer,omitempty" validate:"tf"`          // 是否强制校验付款人身份信息，T:强制校验 / F：不强制
+	IdentityHash  string `json:"identity_hash,omitempty" validate:"max=128"` // 买家身份信息的摘要，用于校验付款人身份，摘要的计算方式请与支付宝约定
 }
 
-// 检查持续时间参数值
-func checkDuration(value string) bool {
-	unit := value[len(value)-1:]
-	if unit != "d" && unit != "m" && unit != "h" && unit != "c" {
-		return false
-	}
-	v := value[:len(value)-1]
-	i, _ := strconv.ParseUint(v, 10, 32)
-	return i == 0
+// BusinessParams 商户传入的业务信息，序列化成JSON字符串后作为business_params参数值发送
+type BusinessParams struct {
+	McCreateTradeIP string            `json:"mc_create_trade_ip,omitempty"` // 商户端创建订单的IP，须上传正确的用户端外网IP，支持IPv4和IPv6
+	CampusCard      string            `json:"campus_card,omitempty"`        // 校园卡编号
+	CardType        string            `json:"card_type,omitempty"`          // 虚拟卡卡类型
+	ActualOrderTime string            `json:"actual_order_time,omitempty"`  // 实际订单时间，在乘车码场景中为用户刷码乘车的时间
+	GoodTaxes       string            `json:"good_taxes,omitempty"`         // 商户传入的交易税费
+	Extra           map[string]string `json:"-"`                            // 额外的业务信息，与支付宝约定的其它参数，不能与已赋值的字段重名
 }
 
-// 检测公用请求参数
-func (r *Params) checkParams() error {
-	if r.AppID == "" {
-		return errors.New("AppID参数未赋值")
-	}
-	appID, _ := strconv.ParseInt(r.AppID, 10, 64)
-	if appID == 0 {
-		return errors.New("AppID参数值只能是数字")
-	}
-	if r.Format != "" && r.Format != "JSON" {
-		return errors.New("Format参数值只能是JSON")
-	}
-	if r.ReturnURL != "" {
-		returnUrlLen := len(r.ReturnURL)
-		if returnUrlLen < 8 {
-			return errors.New("ReturnURL参数值必须是http://或https://开头")
-		}
-		if returnUrlLen > 256 {
-			return errors.New("ReturnURL参数值长度不能大于256")
-		}
-		if r.ReturnURL[0:7] != "http://" && r.ReturnURL[0:8] != "https://" {
-			return errors.New("ReturnURL参数值必须是http://或https://开头")
-		}
-	}
-	if r.Method == "" {
-		r.Method = "alipay.trade.wap.pay"
-	}
-	if r.Charset == "" {
-		return errors.New("Charset参数未赋值")
-	}
-	if len(r.Charset) > 10 {
-		return errors.New("Charset参数值的长度不能大于10")
-	}
-	if r.SignType != "RSA" && r.SignType != "RSA2" {
-		return errors.New("SignType参数值必须是RSA或RSA2")
-	}
-	_, err := time.Parse("2006-01-02 15:04:05", r.Timestamp)
+// InvoiceInfo 开票信息
+type InvoiceInfo struct {
+	KeyInfo *InvoiceKeyInfo `json:"key_info" validate:"required"`             // 必填，开票关键信息
+	Details string          `json:"details" validate:"required,max=400,json"` // 必填，开票内容，JSON数组格式的字符串，如[{"code":"100294400","name":"服饰","num":"2","sumPrice":"200.00","taxRate":"6%"}]
+}
+
+// InvoiceKeyInfo 开票关键信息
+type InvoiceKeyInfo struct {
+	IsSupportInvoice    bool   `json:"is_support_invoice"`                                    // 必填，该交易是否支持开票
+	InvoiceMerchantName string `json:"invoice_merchant_name" validate:"required,maxrunes=80"` // 必填，开票商户名称，格式为商户品牌简称|商户门店简称
+	TaxNum              string `json:"tax_num" validate:"required,max=30"`                    // 必填，税号
+}
+
+// SubMerchant 间连受理商户信息
+type SubMerchant struct {
+	MerchantID   string `json:"merchant_id" validate:"required,max=16"`         // 必填，间连受理商户的支付宝商户编号，通过间连商户入驻后得到
+	MerchantType string `json:"merchant_type,omitempty" validate:"enum=alipay"` // 商户ID类型，alipay：支付宝分配的间连商户编号，目前仅支持alipay，默认可以不传
+}
+
+// MarshalJSON 序列化业务信息，整个JSON对象以JSON字符串的形式输出
+func (obj BusinessParams) MarshalJSON() ([]byte, error) {
+	data, err := obj.marshal()
 	if err != nil {
-		return errors.New("Timestamp的参数值格式不正确")
+		return nil, err
 	}
-	if r.Version != "1" && r.Version != "1.0" {
-		return errors.New("Version的参数值必须是1或者1.0")
+	return json.Marshal(string(data))
+}
+
+// Check 检查额外的业务信息和序列化后的长度
+func (obj *BusinessParams) Check() error {
+	data, err := obj.marshal()
+	if err != nil {
+		return err
 	}
-	if r.NotifyURL != "" {
-		notifyUrlLen := len(r.NotifyURL)
-		if notifyUrlLen < 8 {
-			return errors.New("NotifyURL参数值必须是http://或https://开头")
-		}
-		if notifyUrlLen > 256 {
-			return errors.New("NotifyURL参数值长度不能大于256")
-		}
-		if r.NotifyURL[0:7] != "http://" && r.NotifyURL[0:8] != "https://" {
-			return errors.New("NotifyURL参数值必须是http://或https://开头")
-		}
+	if len(data) > 512 {
+		return errs.MaxLength("BusinessParams", 512)
 	}
 	return nil
 }
 
-// 检查biz_content参数
-func (r *Params) checkBizContent() error {
-	if r.BizContent == nil {
-		r.BizContent = &BizContent{
-			ProductCode: "QUICK_WAP_WAY",
+// 将业务信息序列化成JSON对象，Extra中的参数会合并到对象中
+func (obj *BusinessParams) marshal() ([]byte, error) {
+	type alias BusinessParams
+	data, err := json.Marshal((*alias)(obj))
+	if err != nil || len(obj.Extra) == 0 {
+		return data, err
+	}
+	extra := make(map[string]interface{}, len(obj.Extra))
+	for k := range obj.Extra {
+		extra[k] = obj.Extra[k]
+	}
+	return mergeExtra("BusinessParams.Extra", data, extra)
+}
+
+// MarshalJSON 序列化请求参数，Extra中的参数会合并到biz_content中
+func (obj BizContent) MarshalJSON() ([]byte, error) {
+	return obj.marshal()
+}
+
+// 将请求参数序列化成JSON对象，Extra中的参数会合并到对象中
+func (obj *BizContent) marshal() ([]byte, error) {
+	type alias BizContent
+	data, err := json.Marshal((*alias)(obj))
+	if err != nil || len(obj.Extra) == 0 {
+		return data, err
+	}
+	return mergeExtra("BizContent.Extra", data, obj.Extra)
+}
+
+// 将extra中的参数合并到JSON对象data中，field为错误信息中使用的参数名，extra中的参数与data中已有的参数重名时返回错误
+func mergeExtra(field string, data []byte, extra map[string]interface{}) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, exists := fields[k]; exists {
+			return nil, errs.Invalid(field, errs.RuleExclusive, field+"中的"+k+"参数与已赋值的参数冲突")
 		}
-	}
-	if len(r.BizContent.Body) > 128 {
-		return errors.New("BizContent.Body参数值的长度不能大于128")
-	}
-	if r.BizContent.Subject == "" {
-		return errors.New("BizContent.Subject参数未赋值")
-	}
-	if len(r.BizContent.Subject) > 256 {
-		return errors.New("BizContent.Subject参数值的长度不能大于256")
-	}
-	if r.BizContent.OutTradeNo == "" {
-		return errors.New("BizContent.OutTradeNo参数未赋值")
-	}
-	if len(r.BizContent.OutTradeNo) > 64 {
-		return errors.New("BizContent.OutTradeNo参数值的长度不能大于64")
-	}
-	if r.BizContent.TimeoutExpress != "" && !checkDuration(r.BizContent.TimeoutExpress) {
-		return errors.New("BizContent.TimeoutExpress参数值值的格式不正确")
-	}
-	if r.BizContent.TimeExpire != "" {
-		_, err := time.Parse("2006-01-02 15:04:05", r.BizContent.TimeExpire)
+		value, err := json.Marshal(extra[k])
 		if err != nil {
-			return errors.New("BizContent.TimeExpire的参数值格式不正确")
-		}
-	}
-	if r.BizContent.TotalAmount < 0.01 || r.BizContent.TotalAmount > 100000000 {
-		return errors.New("BizContent.TotalAmount参数值的范围必须是0.01-100000000")
-	}
-	if r.BizContent.ProductCode != "QUICK_WAP_WAY" {
-		return errors.New("BizContent.ProductCod参数值的范围必须是QUICK_WAP_WAY")
-	}
-	if r.BizContent.GoodsType != "0" && r.BizContent.GoodsType != "1" {
-		return errors.New("BizContent.GoodsType参数值只能是0或1")
-	}
-	if len(r.BizContent.PassbackParams) > 512 {
-		return errors.New("BizContent.PassbackParams参数值的长度不能大于512")
-	}
-	if r.BizContent.PromoParams != "" {
-		if len(r.BizContent.PromoParams) > 512 {
-			return errors.New("BizContent.PromoParams参数值的长度不能大于512")
-		}
-		var raw json.RawMessage
-		if json.Unmarshal([]byte(r.BizContent.PromoParams), &raw) != nil {
-			return errors.New("BizContent.PromoParams参数值必须是有效的JSON格式")
-		}
-		r.BizContent.PassbackParams = url.QueryEscape(r.BizContent.PassbackParams)
-	}
-	if r.BizContent.EnablePayChannels != "" && r.BizContent.DisablePayChannels != "" {
-		return errors.New("BizContent.EnablePayChannels与BizContent.DisablePayChannels参数互斥，只能使用其中一个")
-	}
-	if r.BizContent.EnablePayChannels != "" {
-		if len(r.BizContent.EnablePayChannels) > 128 {
-			return errors.New("BizContent.EnablePayChannels参数值的长度不能大于128")
-		}
-	}
-	if r.BizContent.DisablePayChannels != "" {
-		if len(r.BizContent.DisablePayChannels) > 128 {
-			return errors.New("BizContent.DisablePayChannels参数值的长度不能大于128")
+			return nil, err
 		}
+		fields[k] = value
 	}
-	if r.BizContent.QuitURL != "" {
-		if len(r.BizContent.QuitURL) > 400 {
-			return errors.New("BizContent.QuitURL参数值的长度不能大于400")
-		}
+	return json.Marshal(fields)
+}
+
+// SetTimeout 将时长转换成TimeoutExpress参数值，按能整除的最大单位表示，如90分钟转换成90m，2小时转换成2h
+func (obj *BizContent) SetTimeout(d time.Duration) error {
+	value, err := model.TimeoutExpress(d)
+	if err != nil {
+		return err
 	}
+	obj.TimeoutExpress = value
 	return nil
 }
 
-// 检查extend_params参数
-func (r *Params) checkExtendParams() error {
-	if r.BizContent.ExtendParams == nil {
-		return nil
-	}
-	if r.BizContent.ExtendParams.SysServiceProviderID != "" {
-		if len(r.BizContent.ExtendParams.SysServiceProviderID) > 64 {
-			return errors.New("BizContent.ExtendParams.SysServiceProviderID参数值的长度不能大于64")
-		}
-	}
-	if r.BizContent.ExtendParams.NeedBuyerRealnamed != "" {
-		if r.BizContent.ExtendParams.NeedBuyerRealnamed != "T" && r.BizContent.ExtendParams.NeedBuyerRealnamed != "F" {
-			return errors.New("BizContent.ExtendParams.NeedBuyerRealnamed参数值只能是T或F")
-		}
+// SetAutoTruncate 设置是否自动截断超长的参数，开启后Subject超过256个字符、Body超过128个字符时
+// 会在字符边界上截断，而不是校验失败，截断的是签名时使用的副本，BizContent中的值保持不变
+func (r *Params) SetAutoTruncate(value bool) {
+	r.autoTruncate = value
+}
+
+// SetCheckGoodsAmount 设置是否校验GoodsDetail中商品金额的合计等于TotalAmount，用于参加单品营销活动等要求商品明细与订单金额一致的场景
+func (r *Params) SetCheckGoodsAmount(value bool) {
+	r.checkGoods = value
+}
+
+// Validate 按字段的validate标签校验所有请求参数，返回的errs.ValidationErrors中包含全部未通过校验的参数路径和错误信息，
+// 错误信息使用配置的语言，全部通过时返回nil
+func (r *Params) Validate() error {
+	if r.Method == "" {
+		r.Method = "alipay.trade.wap.pay"
 	}
-	if r.BizContent.ExtendParams.TransMemo != "" {
-		if len(r.BizContent.ExtendParams.TransMemo) > 128 {
-			return errors.New("BizContent.ExtendParams.TransMemo参数值的长度不能大于128")
+	if r.BizContent == nil {
+		r.BizContent = &BizContent{
+			ProductCode: "QUICK_WAP_WAY",
 		}
 	}
-	if r.BizContent.ExtendParams.HbFqNum != "" {
-		if r.BizContent.ExtendParams.HbFqNum != "3" && r.BizContent.ExtendParams.HbFqNum != "6" && r.BizContent.ExtendParams.HbFqNum != "12" {
-			return errors.New("BizContent.ExtendParams.HbFqNum参数值只能是3、6、12")
+	r.Charset = charset.Normalize(r.Charset)
+	// 校验截断后的副本，不修改调用者的BizContent
+	params := *r
+	content := r.bizContent()
+	params.BizContent = &content
+	var list errs.ValidationErrors
+	list.Append(validate.Struct("", &params))
+	list.Append(r.checkConfig())
+	// 只报告Extra与字段重名的错误，其它序列化错误在签名时返回
+	if len(r.BizContent.Extra) > 0 {
+		if _, err := r.BizContent.marshal(); err != nil {
+			if _, ok := err.(*errs.ValidationError); ok {
+				list.Append(err)
+			}
 		}
 	}
-	if r.BizContent.ExtendParams.HbFqSellerPercent != "" {
-		if r.BizContent.ExtendParams.HbFqSellerPercent != "100" && r.BizContent.ExtendParams.HbFqSellerPercent != "0" {
-			return errors.New("BizContent.ExtendParams.HbFqSellerPercent参数值只能是0或199")
-		}
+	if r.checkGoods {
+		list.Append(model.CheckGoodsAmount("BizContent.GoodsDetail", r.BizContent.GoodsDetail, "BizContent.TotalAmount", float64(r.BizContent.TotalAmount)))
 	}
-	return nil
+	return r.alipayConfig.Localize(list.Err())
 }
 
-// 检查extend_user_info参数
-func (r *Params) checkExtendUserInfo() error {
-	if r.BizContent.ExtUserInfo == nil {
+// 检查由配置决定的公共参数是否与配置一致，为空的参数和未关联配置时不检查
+func (r *Params) checkConfig() error {
+	if r.alipayConfig == nil {
 		return nil
 	}
-	if r.BizContent.ExtUserInfo.NeedCheckInfo != "" && r.BizContent.ExtUserInfo.NeedCheckInfo != "T" && r.BizContent.ExtUserInfo.NeedCheckInfo != "F" {
-		return errors.New("BizContent.ExtUserInfo.NeedCheckInfo参数值只能是T或F")
-	}
-	if r.BizContent.ExtUserInfo.Name != "" && r.BizContent.ExtUserInfo.NeedCheckInfo == "T" {
-		if len(r.BizContent.ExtUserInfo.Name) > 16 {
-			return errors.New("BizContent.ExtendUserInfo.Name参数值的长度不能大于16")
+	var list errs.ValidationErrors
+	fields := []struct {
+		name   string
+		value  string
+		config string
+		source string
+	}{
+		{"AppID", r.AppID, r.alipayConfig.GetAppID(), "AppID"},
+		{"SignType", r.SignType, r.alipayConfig.GetAppSignType(), "AppSignType"},
+		{"AppCertSN", r.AppCertSN, r.alipayConfig.GetAppCertPublicKeySN(), "AppCertPublicKeySN"},
+		{"AlipayRootCertSN", r.AlipayRootCertSN, r.alipayConfig.GetAlipayRootCertSN(), "AlipayRootCertSN"},
+	}
+	for k := range fields {
+		if fields[k].value != "" && fields[k].value != fields[k].config {
+			list.Append(errs.InvalidKey(fields[k].name, errs.RuleEnum, errs.MsgConfigMismatch, fields[k].source))
 		}
 	}
-	if r.BizContent.ExtUserInfo.CertType != "" && r.BizContent.ExtUserInfo.NeedCheckInfo == "T" {
-		if len(r.BizContent.ExtUserInfo.CertType) > 32 {
-			return errors.New("BizContent.ExtendUserInfo.CertType参数值的长度不能大于32")
-		}
-	}
-	if r.BizContent.ExtUserInfo.CertNo != "" && r.BizContent.ExtUserInfo.NeedCheckInfo == "T" {
-		if len(r.BizContent.ExtUserInfo.CertNo) > 64 {
-			return errors.New("BizContent.ExtendUserInfo.CertNo参数值的长度不能大于64")
-		}
-	}
-	if r.BizContent.ExtUserInfo.MinAge != "" && r.BizContent.ExtUserInfo.NeedCheckInfo == "T" {
-		minAge, err := strconv.ParseUint(r.BizContent.ExtUserInfo.MinAge, 10, 32)
-		if err != nil {
-			return errors.New("BizContent.ExtendUserInfo.MinAge参数值必须是大于或等于0的整数")
-		}
-		if minAge == 0 {
-			return errors.New("BizContent.ExtendUserInfo.MinAge参数值必须是大于或等于0的整数")
-		}
-	}
-	if r.BizContent.ExtUserInfo.FixBuyer != "" {
-		if r.BizContent.ExtUserInfo.FixBuyer != "T" && r.BizContent.ExtUserInfo.FixBuyer != "F" {
-			return errors.New("BizContent.ExtUserInfo.FixBuyer参数值只能是T或F")
-		}
-	}
-	return nil
+	return list.Err()
 }
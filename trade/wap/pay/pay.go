@@ -2,24 +2,25 @@ package pay
 
 import (
 	"errors"
-	"time"
 
 	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
 )
 
 // 生成一个新的默认请求参数
 func New(alipayConfig *config.Config) (*Params, error) {
 	if alipayConfig.GetAppID() == "" {
-		return nil, errors.New("未设置支付宝配置的AppID参数值")
+		return nil, alipayConfig.Localize(errors.New("未设置支付宝配置的AppID参数值"))
 	}
 	if alipayConfig.GetAppSignType() == "" {
-		return nil, errors.New("未设置支付宝配置的AppSignType参数值")
+		return nil, alipayConfig.Localize(errors.New("未设置支付宝配置的AppSignType参数值"))
 	}
 	if alipayConfig.GetAppCertPublicKeySN() == "" {
-		return nil, errors.New("未设置支付宝配置的应用证书")
+		return nil, alipayConfig.Localize(errors.New("未设置支付宝配置的应用证书"))
 	}
 	if alipayConfig.GetAlipayRootCertSN() == "" {
-		return nil, errors.New("未设置支付宝配置的根证书")
+		return nil, alipayConfig.Localize(errors.New("未设置支付宝配置的根证书"))
 	}
 	return &Params{
 		alipayConfig:     alipayConfig,
@@ -27,7 +28,7 @@ func New(alipayConfig *config.Config) (*Params, error) {
 		Method:           "alipay.trade.wap.pay",
 		Charset:          "utf-8",
 		SignType:         alipayConfig.GetAppSignType(),
-		Timestamp:        time.Now().Format("2006-01-02 15:04:05"),
+		Timestamp:        model.Now(),
 		Version:          "1.0",
 		AppCertSN:        alipayConfig.GetAppCertPublicKeySN(),
 		AlipayRootCertSN: alipayConfig.GetAlipayRootCertSN(),
@@ -40,19 +41,36 @@ func New(alipayConfig *config.Config) (*Params, error) {
 			DisablePayChannels: "",
 			StoreID:            "",
 			SpecifiedChannel:   "",
-			BusinessParams:     "",
+			BusinessParams:     nil,
 			ExtUserInfo:        nil,
 		},
 	}, nil
 }
 
-// GetParamsStr 获得参数字符串
+// 从多商户配置注册表中找到指定AppID的配置，生成一个新的默认请求参数
+func NewByAppID(registry *config.Registry, appID string) (*Params, error) {
+	alipayConfig, err := registry.Get(appID)
+	if err != nil {
+		return nil, err
+	}
+	return New(alipayConfig)
+}
+
+// GetParamsStr 获得待签名的参数字符串
 func (r *Params) GetParamsStr() string {
 	return r.paramsStr
 }
 
-// GetURL 获得URL编码后的参数字符串
+// GetURL 获得已签名的支付链接
 func (r *Params) GetURL() string {
-	r.urlValues.Add("sign", r.sign)
-	return APIURL + "?" + r.urlValues.Encode()
+	return r.pageURL
+}
+
+// GetForm 获得以POST方式自动提交到支付宝网关的HTML表单，用于参数过长、不适合放在支付链接中的场景，
+// 必须先调用SignByCert
+func (r *Params) GetForm() (string, error) {
+	if r.request == nil {
+		return "", r.alipayConfig.Localize(errors.New("签名参数未构建"))
+	}
+	return gateway.PageForm(r.alipayConfig, r.request)
 }
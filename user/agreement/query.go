@@ -0,0 +1,84 @@
+package agreement
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 协议查询和解约接口名称
+const (
+	queryMethod  = "alipay.user.agreement.query"
+	unsignMethod = "alipay.user.agreement.unsign"
+)
+
+// 协议状态
+const (
+	StatusTemp   = "TEMP"   // 暂存，协议未生效
+	StatusNormal = "NORMAL" // 正常
+	StatusStop   = "STOP"   // 暂停
+	StatusUnsign = "UNSIGN" // 已解约，仅出现在异步通知中
+)
+
+// QueryBizContent 协议查询和解约的请求参数，AgreementNo与ExternalAgreementNo至少传入一个
+type QueryBizContent struct {
+	AgreementNo         string `json:"agreement_no,omitempty" validate:"required_without=ExternalAgreementNo"`  // 支付宝系统中用以唯一标识用户签约记录的编号
+	ExternalAgreementNo string `json:"external_agreement_no,omitempty"`                                         // 商户签约号
+	PersonalProductCode string `json:"personal_product_code,omitempty" validate:"required_without=AgreementNo"` // 个人签约产品码，使用ExternalAgreementNo时必填
+	SignScene           string `json:"sign_scene,omitempty" validate:"required_without=AgreementNo"`            // 协议签约场景，使用ExternalAgreementNo时必填
+	AlipayUserID        string `json:"alipay_user_id,omitempty"`                                                // 用户的支付宝账号对应的支付宝唯一用户号
+	AlipayLogonID       string `json:"alipay_logon_id,omitempty"`                                               // 用户的支付宝登录账号
+	ThirdPartyType      string `json:"third_party_type,omitempty" validate:"enum=PARTNER|MERCHANT"`             // 签约第三方主体类型，PARTNER或MERCHANT
+}
+
+// QueryResult 协议查询结果
+type QueryResult struct {
+	gateway.Response
+	AgreementNo         string     `json:"agreement_no"`          // 支付宝系统中用以唯一标识用户签约记录的编号
+	ExternalAgreementNo string     `json:"external_agreement_no"` // 商户签约号
+	PersonalProductCode string     `json:"personal_product_code"` // 个人签约产品码
+	SignScene           string     `json:"sign_scene"`            // 协议签约场景
+	Status              string     `json:"status"`                // 协议状态，TEMP、NORMAL或STOP
+	SignTime            model.Time `json:"sign_time"`             // 协议签约时间
+	ValidTime           model.Time `json:"valid_time"`            // 协议生效时间
+	InvalidTime         model.Time `json:"invalid_time"`          // 协议失效时间
+	AlipayLogonID       string     `json:"alipay_logon_id"`       // 用户的支付宝登录账号
+	PrincipalID         string     `json:"principal_id"`          // 签约主体的支付宝用户号
+	PrincipalType       string     `json:"pricipal_type"`         // 签约主体类型，CARD：支付宝账号，CUSTOMER：支付宝用户
+	ThirdPartyType      string     `json:"third_party_type"`      // 签约第三方主体类型
+	ExternalLogonID     string     `json:"external_logon_id"`     // 用户在商户网站的登录账号
+	DeviceID            string     `json:"device_id"`             // 设备ID
+	ZmOpenID            string     `json:"zm_open_id"`            // 用户的芝麻信用openId
+	CreditAuthMode      string     `json:"credit_auth_mode"`      // 授信模式
+	SingleQuota         string     `json:"single_quota"`          // 单笔代扣额度，单位为元
+	NextDeductTime      string     `json:"next_deduct_time"`      // 周期扣协议的预计下次扣款时间，格式为yyyy-MM-dd
+}
+
+// Query 查询用户的代扣协议
+func Query(alipayConfig *config.Config, bizContent *QueryBizContent, options ...gateway.Option) (*QueryResult, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return nil, alipayConfig.Localize(err)
+	}
+
+	var result QueryResult
+	if err := gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     queryMethod,
+		BizContent: bizContent,
+	}, &result, options...); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Unsign 解约用户的代扣协议
+func Unsign(alipayConfig *config.Config, bizContent *QueryBizContent, options ...gateway.Option) error {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return alipayConfig.Localize(err)
+	}
+
+	return gateway.Execute(alipayConfig, &gateway.Request{
+		Method:     unsignMethod,
+		BizContent: bizContent,
+	}, nil, options...)
+}
@@ -0,0 +1,40 @@
+package agreement
+
+import (
+	"github.com/dxvgef/alipay/config"
+	"github.com/dxvgef/alipay/gateway"
+	"github.com/dxvgef/alipay/model"
+	"github.com/dxvgef/alipay/validate"
+)
+
+// 支付宝个人协议页面签约接口名称
+const pageSignMethod = "alipay.user.agreement.page.sign"
+
+// SignBizContent 页面签约请求参数
+type SignBizContent struct {
+	PersonalProductCode string                  `json:"personal_product_code" validate:"required"`                   // 必填，个人签约产品码，周期扣款为CYCLE_PAY_AUTH_P
+	SignScene           string                  `json:"sign_scene,omitempty"`                                        // 协议签约场景，商户与支付宝签约时确定，如INDUSTRY|DIGITAL_MEDIA
+	ExternalAgreementNo string                  `json:"external_agreement_no,omitempty" validate:"max=32"`           // 商户签约号，代扣协议中标示用户的唯一签约号
+	ExternalLogonID     string                  `json:"external_logon_id,omitempty"`                                 // 用户在商户网站的登录账号，用于在签约页面展示
+	AccessParams        *model.AccessParams     `json:"access_params" validate:"required"`                           // 必填，请求签约时的接入渠道
+	PeriodRuleParams    *model.PeriodRuleParams `json:"period_rule_params,omitempty"`                                // 周期管控规则参数，周期扣款时必填
+	ProductCode         string                  `json:"product_code,omitempty"`                                      // 商家和支付宝签约的产品码，周期扣款为CYCLE_PAY_AUTH
+	SignValidityPeriod  string                  `json:"sign_validity_period,omitempty"`                              // 当前用户签约请求的协议有效周期，取值范围：1d～12m
+	ThirdPartyType      string                  `json:"third_party_type,omitempty" validate:"enum=PARTNER|MERCHANT"` // 签约第三方主体类型，PARTNER(平台商户)或MERCHANT(集团商户)，默认为PARTNER
+	MerchantProcessURL  string                  `json:"merchant_process_url,omitempty"`                              // 签约成功后商户用于领取奖励的链接
+	EffectTime          int64                   `json:"effect_time,omitempty"`                                       // 签约有效时间，单位为秒
+}
+
+// BuildSignURL 构建页面签约链接，用户在支付宝中打开后完成签约，签约结果通过异步通知和returnURL获得
+func BuildSignURL(alipayConfig *config.Config, returnURL, notifyURL string, bizContent *SignBizContent, options ...gateway.Option) (string, error) {
+	if err := validate.Struct("BizContent", bizContent); err != nil {
+		return "", alipayConfig.Localize(err)
+	}
+
+	return gateway.PageURL(alipayConfig, &gateway.Request{
+		Method:     pageSignMethod,
+		NotifyURL:  notifyURL,
+		ReturnURL:  returnURL,
+		BizContent: bizContent,
+	}, options...)
+}
@@ -0,0 +1,447 @@
+// Package validate 根据结构体字段的validate标签校验请求参数，供各接口包复用
+//
+// 标签中的多条规则以逗号分隔，按顺序校验，同一字段遇到第一条未通过的规则即停止，支持的规则：
+//
+//	required              必须赋值，切片和map的元素数量不能为0
+//	required_if=F A|B     同一结构体中的F字段值为A或B时必须赋值
+//	required_without=F|G  与同一结构体中的F、G字段至少要赋值一个
+//	max=N                 字符串的字节数或切片的元素数量不能大于N
+//	maxrunes=N            字符串的字符数不能大于N
+//	enum=A|B|C            只能是列出的值之一
+//	tf                    只能是T或F
+//	numeric               只能是数字
+//	uint                  必须是大于或等于0的整数
+//	duration              支付宝的相对时长，1m～15d，单位为m、h、d，或者1c(当天)
+//	datetime              时间格式，默认为yyyy-MM-dd HH:mm:ss，可以用datetime=2006-01-02 15:04指定Go时间格式
+//	url                   必须是http://或https://开头
+//	json                  必须是有效的JSON
+//	amount                最多两位小数的金额字符串
+//	userid                支付宝用户的UserID，以2088开头的16位数字
+//	range=MIN-MAX         数值的范围
+//	gt=N                  数值必须大于N
+//	min=N                 数值不能小于N
+//	exclusive=F           与同一结构体中的F字段互斥，不能同时赋值
+//
+// 除required、required_if、required_without、range、gt和min外，其它规则在字段为零值时跳过。
+// 嵌套的结构体(或结构体指针)字段以及切片中的结构体元素会被递归校验，切片元素的参数路径形如Field[0].Name。
+// 结构体字段的值实现了Checker时，在其字段全部通过校验后再调用Check方法，用于标签无法表达的跨字段规则
+package validate
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dxvgef/alipay/errs"
+)
+
+// 默认的时间格式
+const defaultLayout = "2006-01-02 15:04:05"
+
+// 支付宝的相对时长格式
+var durationRegexp = regexp.MustCompile(`^(\d+)([mhd])$`)
+
+// 金额格式，最多两位小数
+var amountRegexp = regexp.MustCompile(`^\d+(\.\d{1,2})?$`)
+
+// 支付宝UserID格式，以2088开头的16位纯数字
+var userIDRegexp = regexp.MustCompile(`^2088\d{12}$`)
+
+// 字段为零值时仍然需要校验的规则
+var zeroRules = map[string]bool{
+	"range":            true,
+	"gt":               true,
+	"min":              true,
+	"required_if":      true,
+	"required_without": true,
+}
+
+// 各时长单位的上限，均为15天
+var durationLimits = map[string]uint64{
+	"m": 15 * 24 * 60,
+	"h": 15 * 24,
+	"d": 15,
+}
+
+// Go时间格式转换成支付宝文档中的格式
+var layoutReplacer = strings.NewReplacer("2006", "yyyy", "01", "MM", "02", "dd", "15", "HH", "04", "mm", "05", "ss")
+
+// Checker 自定义校验，结构体字段的值实现该接口时，会在其字段全部通过标签校验后调用Check方法，
+// Check返回的参数路径以字段名开头，会被加上字段所在结构体的路径前缀，切片元素不会调用Check方法
+type Checker interface {
+	Check() error
+}
+
+// 一条校验规则
+type rule struct {
+	name  string
+	param string
+}
+
+// Struct 按validate标签校验结构体v及其嵌套的结构体，返回包含全部校验错误的errs.ValidationErrors，全部通过时返回nil。
+// path是v的参数路径，作为错误中参数路径的前缀，v为nil指针时返回path参数未赋值的错误
+func Struct(path string, v interface{}) error {
+	var list errs.ValidationErrors
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			list.Append(errs.Required(path))
+			return list.Err()
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		panic("validate: 只能校验结构体")
+	}
+	checkStruct(&list, prefix(path), value)
+	return list.Err()
+}
+
+// 获得子字段的路径前缀
+func prefix(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + "."
+}
+
+// 校验结构体的每个导出字段
+func checkStruct(list *errs.ValidationErrors, parent string, value reflect.Value) {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag := sf.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		field := value.Field(i)
+		path := parent + sf.Name
+		if err := checkField(value, parent, path, field, parseRules(tag)); err != nil {
+			list.Append(err)
+			continue
+		}
+		checkNested(list, parent, path, field)
+	}
+}
+
+// 校验嵌套的结构体字段和切片中的结构体元素
+func checkNested(list *errs.ValidationErrors, parent, path string, field reflect.Value) {
+	if field.Kind() == reflect.Slice || field.Kind() == reflect.Array {
+		for i := 0; i < field.Len(); i++ {
+			if elem := reflect.Indirect(field.Index(i)); isStruct(elem) {
+				checkStruct(list, path+"["+strconv.Itoa(i)+"].", elem)
+			}
+		}
+		return
+	}
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return
+		}
+	} else if field.CanAddr() {
+		field = field.Addr()
+	}
+	count := len(*list)
+	if elem := reflect.Indirect(field); isStruct(elem) {
+		checkStruct(list, path+".", elem)
+	}
+	if checker, ok := field.Interface().(Checker); ok && len(*list) == count {
+		list.Append(errs.WithPrefix(parent, checker.Check()))
+	}
+}
+
+// 判断是否为需要递归校验的结构体
+func isStruct(value reflect.Value) bool {
+	return value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(time.Time{})
+}
+
+// 解析标签中的规则
+func parseRules(tag string) []rule {
+	if tag == "" {
+		return nil
+	}
+	items := strings.Split(tag, ",")
+	rules := make([]rule, len(items))
+	for k := range items {
+		rules[k].name = items[k]
+		if pos := strings.Index(items[k], "="); pos != -1 {
+			rules[k].name = items[k][:pos]
+			rules[k].param = items[k][pos+1:]
+		}
+	}
+	return rules
+}
+
+// 按顺序校验字段的规则，返回第一条未通过的规则的错误，parentPath为字段所在结构体的路径前缀
+func checkField(parent reflect.Value, parentPath, path string, field reflect.Value, rules []rule) error {
+	for _, r := range rules {
+		if r.name == "required" {
+			if isEmpty(field) {
+				return errs.Required(path)
+			}
+			continue
+		}
+		if !zeroRules[r.name] && isEmpty(field) {
+			continue
+		}
+		if err := checkRule(parent, parentPath, path, field, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 校验一条规则
+func checkRule(parent reflect.Value, parentPath, path string, field reflect.Value, r rule) error {
+	switch r.name {
+	case "max":
+		max := atoi(r)
+		if field.Kind() == reflect.Slice || field.Kind() == reflect.Array || field.Kind() == reflect.Map {
+			if field.Len() > max {
+				return errs.InvalidKey(path, errs.RuleMaxLength, errs.MsgMaxItems, r.param)
+			}
+		} else if len(field.String()) > max {
+			return errs.MaxLength(path, max)
+		}
+	case "maxrunes":
+		if max := atoi(r); utf8.RuneCountInString(field.String()) > max {
+			return errs.MaxRunes(path, max)
+		}
+	case "enum":
+		return checkEnum(path, field, strings.Split(r.param, "|"))
+	case "tf":
+		return checkEnum(path, field, []string{"T", "F"})
+	case "numeric":
+		for _, c := range field.String() {
+			if c < '0' || c > '9' {
+				return errs.InvalidKey(path, errs.RuleFormat, errs.MsgNumeric)
+			}
+		}
+	case "uint":
+		if _, err := strconv.ParseUint(field.String(), 10, 64); err != nil {
+			return errs.InvalidKey(path, errs.RuleFormat, errs.MsgUint)
+		}
+	case "duration":
+		if !isDuration(field.String()) {
+			return errs.InvalidKey(path, errs.RuleFormat, errs.MsgDuration)
+		}
+	case "datetime":
+		layout := r.param
+		if layout == "" {
+			layout = defaultLayout
+		}
+		if _, err := time.Parse(layout, field.String()); err != nil {
+			return errs.InvalidKey(path, errs.RuleFormat, errs.MsgDatetime, layoutReplacer.Replace(layout))
+		}
+	case "url":
+		if !strings.HasPrefix(field.String(), "http://") && !strings.HasPrefix(field.String(), "https://") {
+			return errs.InvalidKey(path, errs.RuleFormat, errs.MsgURL)
+		}
+	case "json":
+		if !json.Valid([]byte(field.String())) {
+			return errs.InvalidKey(path, errs.RuleFormat, errs.MsgJSON)
+		}
+	case "amount":
+		if !amountRegexp.MatchString(field.String()) {
+			return errs.InvalidKey(path, errs.RuleFormat, errs.MsgAmount)
+		}
+	case "userid":
+		if !userIDRegexp.MatchString(field.String()) {
+			return errs.InvalidKey(path, errs.RuleFormat, errs.MsgUserID)
+		}
+	case "range":
+		return checkRange(path, field, r)
+	case "gt", "min":
+		return checkLimit(path, field, r)
+	case "required_if":
+		pos := strings.Index(r.param, " ")
+		if pos == -1 {
+			panic("validate: " + path + "的required_if规则格式无效")
+		}
+		name, values := r.param[:pos], strings.Split(r.param[pos+1:], "|")
+		other := fieldByName(parent, path, r.name, name)
+		if !isEmpty(field) || other.Kind() != reflect.String {
+			return nil
+		}
+		for k := range values {
+			if other.String() == values[k] {
+				return errs.InvalidKey(path, errs.RuleRequired, errs.MsgRequiredIf, parentPath+name, strings.Join(values, "|"))
+			}
+		}
+	case "required_without":
+		if !isEmpty(field) {
+			return nil
+		}
+		names := strings.Split(r.param, "|")
+		paths := make([]string, 0, len(names))
+		for k := range names {
+			if !isEmpty(fieldByName(parent, path, r.name, names[k])) {
+				return nil
+			}
+			paths = append(paths, parentPath+names[k])
+		}
+		return errs.InvalidKey(path, errs.RuleRequired, errs.MsgRequiredWithout, strings.Join(paths, "|"))
+	case "exclusive":
+		if !isEmpty(fieldByName(parent, path, r.name, r.param)) {
+			return errs.InvalidKey(path, errs.RuleExclusive, errs.MsgExclusive, parentPath+r.param)
+		}
+	default:
+		panic("validate: 未知的校验规则" + r.name)
+	}
+	return nil
+}
+
+// 校验枚举值
+func checkEnum(path string, field reflect.Value, values []string) error {
+	value := field.String()
+	if field.Kind() != reflect.String {
+		value = strconv.FormatInt(field.Int(), 10)
+	}
+	for k := range values {
+		if value == values[k] {
+			return nil
+		}
+	}
+	return errs.InvalidKey(path, errs.RuleEnum, errs.MsgEnum, strings.Join(values, "|"))
+}
+
+// 获得同一结构体中name字段的值，规则引用了不存在的字段时panic
+func fieldByName(parent reflect.Value, path, ruleName, name string) reflect.Value {
+	value := parent.FieldByName(name)
+	if !value.IsValid() {
+		panic("validate: " + path + "的" + ruleName + "规则引用了不存在的字段" + name)
+	}
+	return value
+}
+
+// 判断字段是否未赋值，零值和没有元素的切片、map都视为未赋值
+func isEmpty(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.Slice, reflect.Map:
+		return field.Len() == 0
+	}
+	return field.IsZero()
+}
+
+// 校验gt和min规则
+func checkLimit(path string, field reflect.Value, r rule) error {
+	limit, err := strconv.ParseFloat(r.param, 64)
+	if err != nil {
+		panic("validate: " + path + "的" + r.name + "规则的参数必须是数字")
+	}
+	value, err := number(field)
+	if err != nil {
+		return errs.InvalidKey(path, errs.RuleFormat, errs.MsgNumeric)
+	}
+	if r.name == "gt" && value <= limit {
+		return errs.InvalidKey(path, errs.RuleRange, errs.MsgGreater, r.param)
+	}
+	if r.name == "min" && value < limit {
+		return errs.InvalidKey(path, errs.RuleRange, errs.MsgMin, r.param)
+	}
+	return nil
+}
+
+// 获得数值字段的值，字符串字段按数字解析，空字符串视为0
+func number(field reflect.Value) (float64, error) {
+	switch field.Kind() {
+	case reflect.Float32:
+		// float32字段按float32精度转换，避免0.01等值因精度误差被判定为超出范围
+		return strconv.ParseFloat(strconv.FormatFloat(field.Float(), 'g', -1, 32), 64)
+	case reflect.Float64:
+		return field.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), nil
+	}
+	if field.String() == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(field.String(), 64)
+}
+
+// 校验数值范围
+func checkRange(path string, field reflect.Value, r rule) error {
+	pos := strings.Index(r.param, "-")
+	if pos == -1 {
+		panic("validate: " + path + "的range规则格式无效")
+	}
+	min, err := strconv.ParseFloat(r.param[:pos], 64)
+	if err != nil {
+		panic("validate: " + path + "的range规则格式无效")
+	}
+	max, err := strconv.ParseFloat(r.param[pos+1:], 64)
+	if err != nil {
+		panic("validate: " + path + "的range规则格式无效")
+	}
+
+	var value float64
+	switch field.Kind() {
+	case reflect.Float32, reflect.Float64:
+		value = field.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(field.Uint())
+	default:
+		if value, err = strconv.ParseFloat(field.String(), 64); err != nil {
+			return errs.InvalidKey(path, errs.RuleFormat, errs.MsgNumeric)
+		}
+	}
+	// float32字段按float32精度比较，避免0.01等值因精度误差被判定为超出范围
+	if field.Kind() == reflect.Float32 {
+		if float32(value) < float32(min) || float32(value) > float32(max) {
+			return errs.InvalidKey(path, errs.RuleRange, errs.MsgRange, r.param)
+		}
+		return nil
+	}
+	if value < min || value > max {
+		return errs.InvalidKey(path, errs.RuleRange, errs.MsgRange, r.param)
+	}
+	return nil
+}
+
+// Truncate 将value截断到最多max个字符，截断位置总是在字符边界上，不会产生无效的UTF-8编码
+func Truncate(value string, max int) string {
+	if max < 0 {
+		max = 0
+	}
+	count := 0
+	for pos := range value {
+		if count == max {
+			return value[:pos]
+		}
+		count++
+	}
+	return value
+}
+
+// 判断是否为支付宝的相对时长
+func isDuration(value string) bool {
+	if value == "1c" {
+		return true
+	}
+	matches := durationRegexp.FindStringSubmatch(value)
+	if matches == nil {
+		return false
+	}
+	n, err := strconv.ParseUint(matches[1], 10, 64)
+	return err == nil && n > 0 && n <= durationLimits[matches[2]]
+}
+
+// 解析规则中的整数参数
+func atoi(r rule) int {
+	n, err := strconv.Atoi(r.param)
+	if err != nil {
+		panic("validate: " + r.name + "规则的参数必须是整数")
+	}
+	return n
+}
@@ -0,0 +1,238 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/dxvgef/alipay/errs"
+)
+
+type testItem struct {
+	Name  string `validate:"required,max=4"`
+	Count int    `validate:"gt=0"`
+}
+
+type testNested struct {
+	Mode  string `validate:"required,enum=A|B"`
+	Value int
+}
+
+// Check 模式为B时Value不能小于10
+func (obj *testNested) Check() error {
+	if obj.Mode == "B" && obj.Value < 10 {
+		return errs.Invalid("Nested.Value", errs.RuleRange, "Nested.Value参数值在Mode为B时不能小于10")
+	}
+	return nil
+}
+
+type testParams struct {
+	Required          string            `validate:"required"`
+	List              []string          `validate:"max=2"`
+	Max               string            `validate:"max=4"`
+	MaxRunes          string            `validate:"maxrunes=2"`
+	Enum              string            `validate:"enum=A|B|C"`
+	HbFqSellerPercent string            `validate:"enum=0|100"`
+	TF                string            `validate:"tf"`
+	Numeric           string            `validate:"numeric"`
+	MinAge            string            `validate:"uint"`
+	Duration          string            `validate:"duration"`
+	Datetime          string            `validate:"datetime"`
+	Date              string            `validate:"datetime=2006-01-02"`
+	URL               string            `validate:"url"`
+	JSON              string            `validate:"json"`
+	Amount            string            `validate:"amount"`
+	UserID            string            `validate:"userid"`
+	Range             float64           `validate:"range=0.01-100"`
+	Float32Range      float32           `validate:"range=0.01-100"`
+	Gt                int               `validate:"gt=0"`
+	Min               float64           `validate:"min=0.01"`
+	Type              string            `validate:"enum=X|Y"`
+	RequiredIf        string            `validate:"required_if=Type Y"`
+	Either            string            `validate:"required_without=Or"`
+	Or                string            `validate:"-"`
+	Enable            string            `validate:"exclusive=Disable"`
+	Disable           string            `validate:"-"`
+	Items             []testItem        `validate:"required"`
+	Nested            *testNested       `validate:"required"`
+	Extra             map[string]string `validate:"-"`
+}
+
+// 获得能通过全部校验的参数
+func validParams() testParams {
+	return testParams{
+		Required:     "x",
+		Range:        1,
+		Float32Range: 0.01,
+		Gt:           1,
+		Min:          0.01,
+		Either:       "x",
+		Items:        []testItem{{Name: "a", Count: 1}},
+		Nested:       &testNested{Mode: "A"},
+	}
+}
+
+func TestStruct(t *testing.T) {
+	cases := []struct {
+		name  string
+		set   func(p *testParams)
+		field string
+		rule  string
+	}{
+		{"valid", func(p *testParams) {}, "", ""},
+
+		{"required", func(p *testParams) { p.Required = "" }, "P.Required", errs.RuleRequired},
+		{"required empty slice", func(p *testParams) { p.Items = []testItem{} }, "P.Items", errs.RuleRequired},
+		{"required nil pointer", func(p *testParams) { p.Nested = nil }, "P.Nested", errs.RuleRequired},
+
+		{"max", func(p *testParams) { p.Max = "abcd" }, "", ""},
+		{"max exceeded", func(p *testParams) { p.Max = "abcde" }, "P.Max", errs.RuleMaxLength},
+		{"max counts bytes", func(p *testParams) { p.Max = "中文" }, "P.Max", errs.RuleMaxLength},
+		{"max slice", func(p *testParams) { p.List = []string{"a", "b", "c"} }, "P.List", errs.RuleMaxLength},
+
+		{"maxrunes", func(p *testParams) { p.MaxRunes = "中文" }, "", ""},
+		{"maxrunes exceeded", func(p *testParams) { p.MaxRunes = "中文字" }, "P.MaxRunes", errs.RuleMaxLength},
+
+		{"enum", func(p *testParams) { p.Enum = "B" }, "", ""},
+		{"enum invalid", func(p *testParams) { p.Enum = "D" }, "P.Enum", errs.RuleEnum},
+		{"enum 0", func(p *testParams) { p.HbFqSellerPercent = "0" }, "", ""},
+		{"enum 100", func(p *testParams) { p.HbFqSellerPercent = "100" }, "", ""},
+		{"enum 50", func(p *testParams) { p.HbFqSellerPercent = "50" }, "P.HbFqSellerPercent", errs.RuleEnum},
+
+		{"tf", func(p *testParams) { p.TF = "F" }, "", ""},
+		{"tf invalid", func(p *testParams) { p.TF = "true" }, "P.TF", errs.RuleEnum},
+
+		{"numeric", func(p *testParams) { p.Numeric = "2021" }, "", ""},
+		{"numeric invalid", func(p *testParams) { p.Numeric = "12a" }, "P.Numeric", errs.RuleFormat},
+
+		{"uint 0", func(p *testParams) { p.MinAge = "0" }, "", ""},
+		{"uint", func(p *testParams) { p.MinAge = "18" }, "", ""},
+		{"uint negative", func(p *testParams) { p.MinAge = "-1" }, "P.MinAge", errs.RuleFormat},
+		{"uint decimal", func(p *testParams) { p.MinAge = "1.5" }, "P.MinAge", errs.RuleFormat},
+
+		{"duration 1m", func(p *testParams) { p.Duration = "1m" }, "", ""},
+		{"duration 21600m", func(p *testParams) { p.Duration = "21600m" }, "", ""},
+		{"duration 21601m", func(p *testParams) { p.Duration = "21601m" }, "P.Duration", errs.RuleFormat},
+		{"duration 360h", func(p *testParams) { p.Duration = "360h" }, "", ""},
+		{"duration 361h", func(p *testParams) { p.Duration = "361h" }, "P.Duration", errs.RuleFormat},
+		{"duration 15d", func(p *testParams) { p.Duration = "15d" }, "", ""},
+		{"duration 16d", func(p *testParams) { p.Duration = "16d" }, "P.Duration", errs.RuleFormat},
+		{"duration 1c", func(p *testParams) { p.Duration = "1c" }, "", ""},
+		{"duration 2c", func(p *testParams) { p.Duration = "2c" }, "P.Duration", errs.RuleFormat},
+		{"duration 0m", func(p *testParams) { p.Duration = "0m" }, "P.Duration", errs.RuleFormat},
+		{"duration decimal", func(p *testParams) { p.Duration = "1.5h" }, "P.Duration", errs.RuleFormat},
+		{"duration seconds", func(p *testParams) { p.Duration = "30s" }, "P.Duration", errs.RuleFormat},
+
+		{"datetime", func(p *testParams) { p.Datetime = "2021-01-02 15:04:05" }, "", ""},
+		{"datetime invalid", func(p *testParams) { p.Datetime = "2021-01-02T15:04:05" }, "P.Datetime", errs.RuleFormat},
+		{"datetime layout", func(p *testParams) { p.Date = "2021-01-02" }, "", ""},
+		{"datetime layout invalid", func(p *testParams) { p.Date = "2021/01/02" }, "P.Date", errs.RuleFormat},
+
+		{"url", func(p *testParams) { p.URL = "https://example.com/notify" }, "", ""},
+		{"url invalid", func(p *testParams) { p.URL = "ftp://example.com" }, "P.URL", errs.RuleFormat},
+
+		{"json", func(p *testParams) { p.JSON = `{"a":1}` }, "", ""},
+		{"json invalid", func(p *testParams) { p.JSON = `{"a":` }, "P.JSON", errs.RuleFormat},
+
+		{"amount", func(p *testParams) { p.Amount = "0.01" }, "", ""},
+		{"amount integer", func(p *testParams) { p.Amount = "100" }, "", ""},
+		{"amount three decimals", func(p *testParams) { p.Amount = "1.234" }, "P.Amount", errs.RuleFormat},
+		{"amount negative", func(p *testParams) { p.Amount = "-1" }, "P.Amount", errs.RuleFormat},
+
+		{"userid", func(p *testParams) { p.UserID = "2088123456789012" }, "", ""},
+		{"userid prefix", func(p *testParams) { p.UserID = "2089123456789012" }, "P.UserID", errs.RuleFormat},
+		{"userid length", func(p *testParams) { p.UserID = "208812345678901" }, "P.UserID", errs.RuleFormat},
+
+		{"range max", func(p *testParams) { p.Range = 100 }, "", ""},
+		{"range zero", func(p *testParams) { p.Range = 0 }, "P.Range", errs.RuleRange},
+		{"range exceeded", func(p *testParams) { p.Range = 100.01 }, "P.Range", errs.RuleRange},
+		{"range float32", func(p *testParams) { p.Float32Range = 0.009 }, "P.Float32Range", errs.RuleRange},
+
+		{"gt zero", func(p *testParams) { p.Gt = 0 }, "P.Gt", errs.RuleRange},
+		{"min", func(p *testParams) { p.Min = 0.001 }, "P.Min", errs.RuleRange},
+		{"min zero", func(p *testParams) { p.Min = 0 }, "P.Min", errs.RuleRange},
+
+		{"required_if", func(p *testParams) { p.Type = "Y"; p.RequiredIf = "x" }, "", ""},
+		{"required_if other value", func(p *testParams) { p.Type = "X" }, "", ""},
+		{"required_if missing", func(p *testParams) { p.Type = "Y" }, "P.RequiredIf", errs.RuleRequired},
+
+		{"required_without other", func(p *testParams) { p.Either = ""; p.Or = "x" }, "", ""},
+		{"required_without missing", func(p *testParams) { p.Either = "" }, "P.Either", errs.RuleRequired},
+
+		{"exclusive", func(p *testParams) { p.Enable = "a" }, "", ""},
+		{"exclusive both", func(p *testParams) { p.Enable = "a"; p.Disable = "b" }, "P.Enable", errs.RuleExclusive},
+
+		{"slice element", func(p *testParams) { p.Items = append(p.Items, testItem{Name: "abcde", Count: 1}) }, "P.Items[1].Name", errs.RuleMaxLength},
+		{"nested", func(p *testParams) { p.Nested.Mode = "C" }, "P.Nested.Mode", errs.RuleEnum},
+		{"checker", func(p *testParams) { p.Nested.Mode = "B" }, "P.Nested.Value", errs.RuleRange},
+		{"checker passes", func(p *testParams) { p.Nested.Mode = "B"; p.Nested.Value = 10 }, "", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := validParams()
+			c.set(&p)
+			err := Struct("P", &p)
+			if c.field == "" {
+				if err != nil {
+					t.Fatalf("期望通过校验，得到%v", err)
+				}
+				return
+			}
+			list, ok := err.(errs.ValidationErrors)
+			if !ok || len(list) != 1 {
+				t.Fatalf("期望一个校验错误，得到%v", err)
+			}
+			if list[0].Field != c.field || list[0].Rule != c.rule {
+				t.Fatalf("期望%s参数的%s错误，得到%s参数的%s错误：%s", c.field, c.rule, list[0].Field, list[0].Rule, list[0].Message)
+			}
+		})
+	}
+}
+
+func TestStructReportsAll(t *testing.T) {
+	p := validParams()
+	p.Required = ""
+	p.Enum = "D"
+	p.Items = []testItem{{Count: 1}, {Name: "a"}}
+	p.Nested.Mode = ""
+
+	err := Struct("P", &p)
+	list, ok := err.(errs.ValidationErrors)
+	if !ok {
+		t.Fatalf("期望errs.ValidationErrors，得到%v", err)
+	}
+	expected := []string{"P.Required", "P.Enum", "P.Items[0].Name", "P.Items[1].Count", "P.Nested.Mode"}
+	if len(list) != len(expected) {
+		t.Fatalf("期望%d个校验错误，得到%v", len(expected), err)
+	}
+	for k := range expected {
+		if list[k].Field != expected[k] {
+			t.Errorf("第%d个错误期望%s参数，得到%s", k, expected[k], list[k].Field)
+		}
+	}
+}
+
+func TestStructNil(t *testing.T) {
+	err := Struct("BizContent", (*testParams)(nil))
+	list, ok := err.(errs.ValidationErrors)
+	if !ok || len(list) != 1 || list[0].Field != "BizContent" || list[0].Rule != errs.RuleRequired {
+		t.Fatalf("期望BizContent参数未赋值的错误，得到%v", err)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		value    string
+		max      int
+		expected string
+	}{
+		{"abc", 5, "abc"},
+		{"abc", 2, "ab"},
+		{"中文字符", 2, "中文"},
+		{"中文", 0, ""},
+		{"中文", -1, ""},
+	}
+	for _, c := range cases {
+		if result := Truncate(c.value, c.max); result != c.expected {
+			t.Errorf("Truncate(%q, %d)期望%q，得到%q", c.value, c.max, c.expected, result)
+		}
+	}
+}